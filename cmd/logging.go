@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Log formats selected with --log-format
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var (
+	logFile   string
+	logFormat string
+	logLevel  string
+)
+
+// newLogger creates the logger writing to --log-file, or to stdout in debug
+// mode, and discarding everything otherwise
+// --log-level only applies when there is somewhere to write to
+func newLogger() (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", logLevel, err)
+	}
+
+	var w io.Writer
+	switch {
+	case logFile != "":
+		file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = file
+	case debug:
+		w = os.Stdout
+	default:
+		w = io.Discard
+		level = slog.LevelError + 1
+	}
+
+	options := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       level,
+		ReplaceAttr: shortSource,
+	}
+	switch logFormat {
+	case logFormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case logFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: expected %q or %q", logFormat, logFormatText, logFormatJSON)
+	}
+}
+
+// shortSource logs the source file without its directory, e.g. "game.go:284"
+func shortSource(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.SourceKey {
+		return a
+	}
+	if source, ok := a.Value.Any().(*slog.Source); ok {
+		a.Value = slog.StringValue(fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line))
+	}
+	return a
+}
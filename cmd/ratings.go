@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+	"github.com/spf13/cobra"
+)
+
+var ratingsCmd = &cobra.Command{
+	Use:   "ratings",
+	Short: "List the persisted skill ratings",
+	Long: `List the skill ratings persisted across events in the ratings file
+configured in config.json, highest rating first. Use it to seed players
+fairly in future tournaments.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadFromFile("config.json")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if cfg.RatingsFile == "" {
+			log.Fatal("ratings_file is not set in config.json")
+		}
+
+		ratings, err := models.LoadRatings(cfg.RatingsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("%-6s %-24s %8s %8s\n", "Seed", "Name", "Rating", "Rounds")
+		for i, name := range ratings.Names() {
+			rating := ratings.Players[name]
+			fmt.Printf("%-6d %-24s %8.0f %8d\n", i+1, name, rating.Value, rating.Rounds)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ratingsCmd)
+}
@@ -1,20 +1,32 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/metrics"
 	"github.com/fjerlv/deathquake-go/models"
 	"github.com/fjerlv/deathquake-go/parser"
+	"github.com/fjerlv/deathquake-go/rcon"
+	"github.com/fjerlv/deathquake-go/status"
 	"github.com/fjerlv/deathquake-go/ui"
+	"github.com/fjerlv/deathquake-go/web"
+	"github.com/fjerlv/deathquake-go/webhook"
 	"github.com/spf13/cobra"
-	"io"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 var (
-	filename string
-	debug    bool
+	filename    string
+	debug       bool
+	httpAddr    string
+	metricsAddr string
 )
 
 var rootCmd = &cobra.Command{
@@ -26,9 +38,22 @@ live player statistics, rankings, and match information in your terminal.
 The tool tracks kills, deaths, weapon usage, killing streaks, and more,
 with a fun beer/cider scoring system for match performance.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// Read from stdin with -f - or when the log is piped in
+		if filename == "-" || (filename == "" && !stdinIsTerminal()) {
+			cfg, logger, game := setup()
+			subscribers := startSubscribers(ctx, cfg, "", logger)
+			track(ctx, cfg, game, subscribers, func(ctx context.Context, subscriber parser.Subscriber) error {
+				return parser.TailReader(ctx, os.Stdin, subscriber, game, logger)
+			}, logger)
+			return
+		}
+
 		// Check if filename is provided
 		if filename == "" {
-			log.Fatal("filename is required (use -f or --filename)")
+			log.Fatal("filename is required (use -f or --filename, or pipe the log to stdin)")
 		}
 
 		// Check if file exists
@@ -39,42 +64,168 @@ with a fun beer/cider scoring system for match performance.`,
 			log.Fatalf("error accessing file %s: %v", filename, err)
 		}
 
-		// Load config.json from current directory
-		cfg, err := config.LoadFromFile("config.json")
+		cfg, logger, game := setup()
+		subscribers := startSubscribers(ctx, cfg, filename, logger)
+		track(ctx, cfg, game, subscribers, func(ctx context.Context, subscriber parser.Subscriber) error {
+			return parser.Tail(ctx, filename, subscriber, game, logger)
+		}, logger)
+	},
+}
+
+// setup loads config.json from the current directory and creates the logger and game
+func setup() (*config.Config, *slog.Logger, *models.Game) {
+	cfg, err := config.LoadFromFile("config.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := newLogger()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	game := models.NewGame(cfg, logger)
+
+	// Seed players with the skill ratings from previous events
+	if cfg.RatingsFile != "" {
+		ratings, err := models.LoadRatings(cfg.RatingsFile)
 		if err != nil {
 			log.Fatal(err)
 		}
+		game.Ratings = ratings
+	}
 
-		// Create logger based on debug mode
-		var logger *log.Logger
-		if debug {
-			logger = log.New(os.Stdout, "[DEBUG] ", log.Lshortfile)
-		} else {
-			logger = log.New(io.Discard, "", 0)
-		}
+	return cfg, logger, game
+}
 
-		game := models.NewGame(cfg, logger)
+// startSubscribers starts the optional outputs enabled by flags and config,
+// running in the background until ctx is cancelled
+// logFile is the game log being tracked, used to report parser lag
+func startSubscribers(ctx context.Context, cfg *config.Config, logFile string, logger *slog.Logger) parser.Subscribers {
+	var subscribers parser.Subscribers
 
-		if debug {
-			// Debug mode: run without UI
-			if err := parser.Tail(filename, nil, game, logger); err != nil {
+	// Optional web dashboard
+	if httpAddr != "" {
+		server := web.NewServer(logger)
+		subscribers = append(subscribers, server)
+		go func() {
+			if err := server.ListenAndServe(httpAddr); err != nil {
 				log.Fatal(err)
 			}
-		} else {
-			// Normal mode: run with tea UI
-			program := tea.NewProgram(ui.NewModel())
-
-			go func() {
-				if err := parser.Tail(filename, program, game, logger); err != nil {
-					log.Fatal(err)
-				}
-			}()
+		}()
+	}
 
-			if err := program.Start(); err != nil {
+	// Optional Prometheus metrics
+	if metricsAddr != "" {
+		exporter := metrics.NewExporter(logFile, logger)
+		subscribers = append(subscribers, exporter)
+		go func() {
+			if err := exporter.ListenAndServe(metricsAddr); err != nil {
 				log.Fatal(err)
 			}
+		}()
+	}
+
+	// Optional webhook notifications
+	if cfg.WebhookURL != "" {
+		notifier, err := webhook.NewNotifier(cfg.WebhookURL, cfg.WebhookQueueFile, logger)
+		if err != nil {
+			log.Fatal(err)
 		}
-	},
+		subscribers = append(subscribers, notifier)
+		go notifier.Run(ctx)
+	}
+
+	// Optional in-game announcements
+	if cfg.Rcon.Address != "" {
+		client := rcon.NewClient(cfg.Rcon.Address, cfg.Rcon.Password)
+		announcer := rcon.NewAnnouncer(client, cfg.Rcon.KillStreak, logger)
+		subscribers = append(subscribers, announcer)
+		go announcer.Run(ctx)
+	}
+
+	return subscribers
+}
+
+// track runs tail with the terminal UI, or without it in debug mode, until
+// ctx is cancelled or the UI is closed, and then finishes the event
+func track(ctx context.Context, cfg *config.Config, game *models.Game, subscribers parser.Subscribers, tail func(ctx context.Context, subscriber parser.Subscriber) error, logger *slog.Logger) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if debug {
+		// Debug mode: run without UI
+		startPoller(ctx, cfg, game, subscribers, logger)
+		if err := tail(ctx, subscribers); err != nil {
+			log.Fatal(err)
+		}
+		finish(cfg, game)
+		return
+	}
+
+	// Normal mode: run with tea UI
+	// The keyboard is read from the terminal when the log is piped to stdin
+	var options []tea.ProgramOption
+	if !stdinIsTerminal() {
+		options = append(options, tea.WithInputTTY())
+	}
+	program := tea.NewProgram(ui.NewModel(), options...)
+	subscribers = append(subscribers, ui.NewProgramSubscriber(program))
+	startPoller(ctx, cfg, game, subscribers, logger)
+
+	tailed := make(chan struct{})
+	go func() {
+		defer close(tailed)
+		if err := tail(ctx, subscribers); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// Close the UI on SIGINT or SIGTERM
+	go func() {
+		<-ctx.Done()
+		program.Quit()
+	}()
+
+	if err := program.Start(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Stop tailing before the game is read for the last time
+	cancel()
+	<-tailed
+	finish(cfg, game)
+}
+
+// finish writes the final state of the game to disk and prints the summary
+func finish(cfg *config.Config, game *models.Game) {
+	snapshot := game.Snapshot()
+	if err := snapshot.SaveToFile(cfg.StateFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Final state written to %s\n", cfg.StateFile)
+	}
+	fmt.Print(snapshot.Summary())
+}
+
+// stdinIsTerminal reports whether stdin is a terminal rather than a pipe or file
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// startPoller polls the server status in the background when configured,
+// until ctx is cancelled
+func startPoller(ctx context.Context, cfg *config.Config, game *models.Game, subscriber status.Subscriber, logger *slog.Logger) {
+	if cfg.Status.Address == "" {
+		return
+	}
+	interval := time.Duration(cfg.Status.IntervalSeconds) * time.Second
+	poller := status.NewPoller(cfg.Status.Address, interval, subscriber, logger)
+	go poller.Run(ctx)
 }
 
 func Execute() {
@@ -85,12 +236,26 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&filename, "filename", "f", "", "Path to the Quake 3 game log file (required)")
-	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().StringVarP(&filename, "filename", "f", "", "Path to the Quake 3 game log file, or - for stdin (required unless piped)")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Log to stdout instead of showing the terminal UI")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file, also while the terminal UI is shown")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormatText, "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "debug", "Minimum log level: debug, info, warn or error (only with --log-file or --debug, logs are discarded otherwise)")
+	rootCmd.Flags().StringVar(&httpAddr, "http", "", "Serve a live web dashboard on this address (e.g. :8080)")
+	rootCmd.Flags().StringVar(&metricsAddr, "metrics", "", "Serve Prometheus metrics on this address (e.g. :9100)")
 
 	rootCmd.Example = `  # Monitor a game log (requires config.json in current directory)
   deathquake-go -f /path/to/games.log
 
   # Using relative path
-  deathquake-go -f games.log`
+  deathquake-go -f games.log
+
+  # Pipe the server output straight in, timestamps are added when missing
+  ioq3ded.x86_64 +exec server.cfg 2>&1 | deathquake-go -f -
+
+  # Also show the standings in a browser on port 8080
+  deathquake-go -f games.log --http :8080
+
+  # Write JSON logs to a file while the terminal UI is shown
+  deathquake-go -f games.log --log-file deathquake.log --log-format json`
 }
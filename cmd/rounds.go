@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+	"github.com/fjerlv/deathquake-go/parser"
+	"github.com/spf13/cobra"
+)
+
+var roundsCmd = &cobra.Command{
+	Use:   "rounds",
+	Short: "List the rounds in a game log with their ids",
+	Long: `Read a game log and list every round with its id, map, start time
+and winner. Put the id of a round in ignored_rounds in config.json to leave
+it out of the standings. Rounds that are already ignored, by their id, their
+legacy hash or the ignore_rules, are listed with the reason.`,
+	Example: `  deathquake-go rounds -f game_20251206_143022.log
+  deathquake-go rounds -f /path/to/ioquake3/baseq3/games.log`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if filename == "" {
+			log.Fatal("filename is required (use -f or --filename)")
+		}
+		cfg, err := config.LoadFromFile("config.json")
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger, err := newLogger()
+		if err != nil {
+			log.Fatal(err)
+		}
+		file, err := os.Open(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		// Leave the ratings file alone
+		cfg.RatingsFile = ""
+
+		game := models.NewGame(cfg, logger)
+		if err := parser.TailReader(context.Background(), file, nil, game, logger); err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Id\tMap\tStarted\tWinner\tIgnored")
+		for _, round := range game.Rounds {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				round.Id, round.MapName, round.StartedAt, round.Winner(), round.IgnoredReason)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(roundsCmd)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/ioq3"
+	"github.com/fjerlv/deathquake-go/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ioq3Dir       string
+	ioq3Binary    string
+	serverCfg     string
+	serveLogFile  string
+	rconPassword  string
+	trackInServer bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the Quake 3 dedicated server and log the game",
+	Long: `Start the ioquake3 dedicated server, timestamp its output and append it
+to a log file in the current directory. The server is restarted if it
+crashes, and the log file is kept so the event continues where it left off.
+
+With --track the game is also tracked in the same process, without
+reading the log file back from disk.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if ioq3Dir == "" {
+			log.Fatal("ioquake3 directory is required (use --ioq3)")
+		}
+
+		cfg, logger, game := setup()
+
+		// Use the flag or config.json, or make one up for the announcements
+		if rconPassword == "" {
+			rconPassword = cfg.Rcon.Password
+		}
+		if rconPassword == "" {
+			rconPassword = generatePassword()
+			fmt.Fprintln(os.Stderr, "Generated an rcon password, set --rcon-password to use rcon yourself")
+		}
+		cfg.Rcon.Password = rconPassword
+
+		if serveLogFile == "" {
+			serveLogFile = fmt.Sprintf("game_%s.log", time.Now().Format("20060102_150405"))
+		}
+
+		launcher := ioq3.NewLauncher(ioq3Dir, serverCfg, serveLogFile, logger)
+		launcher.Binary = ioq3Binary
+		launcher.RconPassword = rconPassword
+		if err := launcher.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(os.Stderr, "Logging to %s\n", serveLogFile)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if !trackInServer {
+			// Show the server output in the terminal, like tee
+			launcher.Output = os.Stdout
+			if err := launcher.Run(ctx); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		lines := make(chan string, 1024)
+		launcher.Lines = lines
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			// Catch up on the event so far when continuing an existing log
+			if err := replayLog(ctx, serveLogFile, lines); err != nil {
+				log.Fatal(err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err := launcher.Run(ctx); err != nil {
+				log.Fatal(err)
+			}
+		}()
+
+		subscribers := startSubscribers(ctx, cfg, serveLogFile, logger)
+		track(ctx, cfg, game, subscribers, func(ctx context.Context, subscriber parser.Subscriber) error {
+			return parser.TailLines(ctx, lines, subscriber, game, logger)
+		}, logger)
+
+		// Tracking ended, take the server down with it
+		stop()
+		<-done
+	},
+}
+
+// replayLog sends the lines already in the log file, if it exists, until ctx is cancelled
+func replayLog(ctx context.Context, fileName string, lines chan<- string) error {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		select {
+		case lines <- scanner.Text():
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// generatePassword returns a random rcon password
+func generatePassword() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&ioq3Dir, "ioq3", "", "Path to the ioquake3 installation (required)")
+	serveCmd.Flags().StringVar(&ioq3Binary, "binary", ioq3.DefaultBinary, "Dedicated server binary in the ioquake3 directory")
+	serveCmd.Flags().StringVar(&serverCfg, "config", "server.cfg", "Server configuration to install and execute")
+	serveCmd.Flags().StringVar(&serveLogFile, "log", "", "Log file to append to (default game_YYYYMMDD_HHMMSS.log)")
+	serveCmd.Flags().StringVar(&rconPassword, "rcon-password", "", "Rcon password (default rcon.password from config.json, or generated)")
+	serveCmd.Flags().BoolVar(&trackInServer, "track", false, "Track the game in the same process")
+	serveCmd.Flags().StringVar(&httpAddr, "http", "", "Serve a live web dashboard on this address (e.g. :8080)")
+	serveCmd.Flags().StringVar(&metricsAddr, "metrics", "", "Serve Prometheus metrics on this address (e.g. :9100)")
+
+	serveCmd.Example = `  # Start the server and log to game_YYYYMMDD_HHMMSS.log
+  deathquake-go serve --ioq3 /opt/ioquake3
+
+  # Start the server and show the standings in the same terminal
+  deathquake-go serve --ioq3 /opt/ioquake3 --track
+
+  # Continue an event in an existing log after a reboot
+  deathquake-go serve --ioq3 /opt/ioquake3 --log game_20251206_143022.log`
+
+	rootCmd.AddCommand(serveCmd)
+}
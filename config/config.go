@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 // Config holds configuration for the game parser
@@ -18,8 +19,152 @@ type Config struct {
 
 	// IgnoredRounds is a list of round identifiers to skip
 	IgnoredRounds []string `json:"ignored_rounds"`
+
+	// IgnoreRules ignore rounds by criteria when they end
+	IgnoreRules IgnoreRules `json:"ignore_rules"`
+
+	// RatingsFile is the path of the JSON file where skill ratings are
+	// persisted across events (ratings are not persisted when empty)
+	RatingsFile string `json:"ratings_file"`
+
+	// StateFile is where the final state of the game is written on exit
+	StateFile string `json:"state_file"`
+
+	// WebhookURL is a Discord or Slack compatible webhook that receives the
+	// round results (no notifications are sent when empty)
+	WebhookURL string `json:"webhook_url"`
+
+	// WebhookQueueFile is where undelivered webhook messages are kept
+	WebhookQueueFile string `json:"webhook_queue_file"`
+
+	// Rcon configures announcements in the game chat
+	Rcon RconConfig `json:"rcon"`
+
+	// Status configures polling the server status
+	Status StatusConfig `json:"status"`
+
+	// Judges may run commands such as !skip from the game chat
+	Judges []string `json:"judges"`
+
+	// BotPolicy is how bots are counted: "count", "exclude", "hide" or
+	// "reduce" (default "count")
+	BotPolicy string `json:"bot_policy"`
+
+	// BotKillWeight is what a kill against a bot is worth compared to a kill
+	// against a player with the "reduce" bot policy
+	BotKillWeight float64 `json:"bot_kill_weight"`
+
+	// LateJoinSeconds is how long after the map started a player can join
+	// before the round is flagged as a late join for them
+	LateJoinSeconds int `json:"late_join_seconds"`
+}
+
+// DefaultLateJoinSeconds is used when no late join limit is configured
+const DefaultLateJoinSeconds = 60
+
+// Bot policies
+const (
+	// BotPolicyCount counts bots as regular players
+	BotPolicyCount = "count"
+
+	// BotPolicyExclude ignores bots and every kill involving a bot
+	BotPolicyExclude = "exclude"
+
+	// BotPolicyHide tracks bots without showing or ranking them
+	BotPolicyHide = "hide"
+
+	// BotPolicyReduce hides bots like BotPolicyHide and counts kills
+	// against bots at BotKillWeight
+	BotPolicyReduce = "reduce"
+)
+
+// DefaultBotKillWeight is used when no bot kill weight is configured
+const DefaultBotKillWeight = 0.5
+
+// StatusConfig holds how the server status is polled
+type StatusConfig struct {
+	// Address of the server, e.g. "127.0.0.1:27960" (the server is not
+	// polled when empty)
+	Address string `json:"address"`
+
+	// IntervalSeconds is the time between polls
+	IntervalSeconds int `json:"interval_seconds"`
+}
+
+// DefaultStatusIntervalSeconds is used when no status poll interval is configured
+const DefaultStatusIntervalSeconds = 5
+
+// RconConfig holds the rcon connection to the Quake 3 server
+type RconConfig struct {
+	// Address of the server, e.g. "127.0.0.1:27960" (no announcements
+	// are made when empty)
+	Address string `json:"address"`
+
+	// Password is the rconpassword set on the server
+	Password string `json:"password"`
+
+	// KillStreak announces every this many kills in a row (kill streaks
+	// are not announced when 0)
+	KillStreak int `json:"kill_streak"`
+}
+
+// IgnoreRules are criteria for ignoring rounds automatically when they end
+type IgnoreRules struct {
+	// MinDurationSeconds ignores rounds shorter than this (no minimum when 0)
+	MinDurationSeconds int `json:"min_duration_seconds"`
+
+	// MinPlayers ignores rounds with fewer players (no minimum when 0)
+	MinPlayers int `json:"min_players"`
+
+	// Periods ignores rounds started in any of these times of the day
+	Periods []Period `json:"periods"`
+
+	// Maps ignores rounds on any of these maps
+	Maps []string `json:"maps"`
+
+	// EndedByMapChange ignores rounds ended by a map change, e.g. with rcon,
+	// rather than by Exit with a scoreboard (default true)
+	EndedByMapChange *bool `json:"ended_by_map_change"`
+}
+
+// IgnoresMapChanges returns true if rounds ended by a map change are ignored
+func (r IgnoreRules) IgnoresMapChanges() bool {
+	return r.EndedByMapChange == nil || *r.EndedByMapChange
 }
 
+// PeriodLayout is the layout of the times in a Period
+const PeriodLayout = "15:04"
+
+// Period is a time of the day from From up to To, e.g. "18:00" to "18:30"
+// Periods with To before From span midnight
+type Period struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Contains returns true if the time of the day is in the period
+func (p Period) Contains(t time.Time) bool {
+	from, _ := time.Parse(PeriodLayout, p.From)
+	to, _ := time.Parse(PeriodLayout, p.To)
+	minute := t.Hour()*60 + t.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := to.Hour()*60 + to.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func (p Period) String() string {
+	return p.From + "-" + p.To
+}
+
+// DefaultStateFile is used when no state file is configured
+const DefaultStateFile = "deathquake_state.json"
+
+// DefaultWebhookQueueFile is used when no webhook queue file is configured
+const DefaultWebhookQueueFile = "webhook_queue.json"
+
 // LoadFromFile loads configuration from a JSON file
 func LoadFromFile(filepath string) (*Config, error) {
 	data, err := os.ReadFile(filepath)
@@ -35,5 +180,38 @@ func LoadFromFile(filepath string) (*Config, error) {
 	// Always append <world> to ignored players
 	cfg.IgnoredPlayers = append(cfg.IgnoredPlayers, "<world>")
 
+	if cfg.StateFile == "" {
+		cfg.StateFile = DefaultStateFile
+	}
+	if cfg.WebhookQueueFile == "" {
+		cfg.WebhookQueueFile = DefaultWebhookQueueFile
+	}
+	if cfg.Status.IntervalSeconds <= 0 {
+		cfg.Status.IntervalSeconds = DefaultStatusIntervalSeconds
+	}
+	if cfg.LateJoinSeconds <= 0 {
+		cfg.LateJoinSeconds = DefaultLateJoinSeconds
+	}
+
+	switch cfg.BotPolicy {
+	case "":
+		cfg.BotPolicy = BotPolicyCount
+	case BotPolicyCount, BotPolicyExclude, BotPolicyHide, BotPolicyReduce:
+	default:
+		return nil, fmt.Errorf("invalid bot_policy %q: expected %q, %q, %q or %q",
+			cfg.BotPolicy, BotPolicyCount, BotPolicyExclude, BotPolicyHide, BotPolicyReduce)
+	}
+	if cfg.BotKillWeight <= 0 {
+		cfg.BotKillWeight = DefaultBotKillWeight
+	}
+
+	for _, period := range cfg.IgnoreRules.Periods {
+		for _, value := range []string{period.From, period.To} {
+			if _, err := time.Parse(PeriodLayout, value); err != nil {
+				return nil, fmt.Errorf("invalid ignore_rules period %q: expected HH:MM", value)
+			}
+		}
+	}
+
 	return &cfg, nil
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadFromFile(t *testing.T) {
@@ -49,6 +50,10 @@ func TestLoadFromFile(t *testing.T) {
 	if cfg.IgnoredRounds[0] != "5d41402abc4b2a76b9719d911017c592" || cfg.IgnoredRounds[1] != "7d793037a0760186574b0282f2f435e7" {
 		t.Errorf("Ignored rounds not loaded correctly: %v", cfg.IgnoredRounds)
 	}
+
+	if cfg.StateFile != DefaultStateFile {
+		t.Errorf("Expected default state file %q, got %q", DefaultStateFile, cfg.StateFile)
+	}
 }
 
 func TestLoadFromFile_NonExistent(t *testing.T) {
@@ -77,3 +82,116 @@ func TestLoadFromFile_InvalidJSON(t *testing.T) {
 		t.Error("Expected error for invalid JSON, got nil")
 	}
 }
+
+func TestLoadFromFile_WebhookQueueFileDefault(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "config-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write([]byte(`{"webhook_url": "http://localhost/hook"}`)); err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+
+	cfg, err := LoadFromFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	if cfg.WebhookURL != "http://localhost/hook" {
+		t.Errorf("Expected webhook URL to be loaded, got %q", cfg.WebhookURL)
+	}
+	if cfg.WebhookQueueFile != DefaultWebhookQueueFile {
+		t.Errorf("Expected default webhook queue file, got %q", cfg.WebhookQueueFile)
+	}
+	if cfg.LateJoinSeconds != DefaultLateJoinSeconds {
+		t.Errorf("Expected default late join limit, got %d", cfg.LateJoinSeconds)
+	}
+}
+
+func TestLoadFromFile_BotPolicy(t *testing.T) {
+	tests := []struct {
+		config  string
+		policy  string
+		weight  float64
+		wantErr bool
+	}{
+		{`{}`, BotPolicyCount, DefaultBotKillWeight, false},
+		{`{"bot_policy": "reduce", "bot_kill_weight": 0.25}`, BotPolicyReduce, 0.25, false},
+		{`{"bot_policy": "kick"}`, "", 0, true},
+	}
+	for _, tt := range tests {
+		tmpFile, err := os.CreateTemp("", "config-*.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+		if _, err := tmpFile.Write([]byte(tt.config)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadFromFile(tmpFile.Name())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadFromFile(%s) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (cfg.BotPolicy != tt.policy || cfg.BotKillWeight != tt.weight) {
+			t.Errorf("LoadFromFile(%s) = %q and %.2f, expected %q and %.2f", tt.config, cfg.BotPolicy, cfg.BotKillWeight, tt.policy, tt.weight)
+		}
+	}
+}
+
+func TestLoadFromFile_IgnoreRules(t *testing.T) {
+	tests := []struct {
+		config     string
+		mapChanges bool
+		wantErr    bool
+	}{
+		{`{}`, true, false},
+		{`{"ignore_rules": {"min_players": 3, "periods": [{"from": "18:00", "to": "18:30"}], "ended_by_map_change": false}}`, false, false},
+		{`{"ignore_rules": {"periods": [{"from": "6pm", "to": "18:30"}]}}`, false, true},
+	}
+	for _, tt := range tests {
+		tmpFile, err := os.CreateTemp("", "config-*.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+		if _, err := tmpFile.Write([]byte(tt.config)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadFromFile(tmpFile.Name())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadFromFile(%s) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+			continue
+		}
+		if err == nil && cfg.IgnoreRules.IgnoresMapChanges() != tt.mapChanges {
+			t.Errorf("LoadFromFile(%s) ignores map changes = %v, expected %v", tt.config, !tt.mapChanges, tt.mapChanges)
+		}
+	}
+}
+
+func TestPeriod_Contains(t *testing.T) {
+	tests := []struct {
+		period Period
+		time   string
+		want   bool
+	}{
+		{Period{From: "18:00", To: "18:30"}, "18:00", true},
+		{Period{From: "18:00", To: "18:30"}, "18:29", true},
+		{Period{From: "18:00", To: "18:30"}, "18:30", false},
+		{Period{From: "23:30", To: "00:30"}, "00:10", true},
+		{Period{From: "23:30", To: "00:30"}, "12:00", false},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(PeriodLayout, tt.time)
+		if got := tt.period.Contains(at); got != tt.want {
+			t.Errorf("%s contains %s = %v, expected %v", tt.period, tt.time, got, tt.want)
+		}
+	}
+}
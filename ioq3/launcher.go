@@ -0,0 +1,242 @@
+package ioq3
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/parser"
+)
+
+const (
+	// DefaultBinary is the dedicated server binary in the ioquake3 directory
+	DefaultBinary = "ioq3ded.x86_64"
+
+	defaultRestartDelay = 3 * time.Second
+
+	// shutdownTimeout is how long the server gets to quit after SIGTERM
+	// before it is killed
+	shutdownTimeout = 5 * time.Second
+
+	// rconCfg is executed before server.cfg to set the rcon password
+	rconCfg = "deathquake_rcon.cfg"
+)
+
+// Launcher runs the ioquake3 dedicated server, timestamps its output into a
+// log file and restarts it when it crashes
+type Launcher struct {
+	// Dir is the ioquake3 installation directory
+	Dir string
+
+	// Binary is the server binary in Dir
+	Binary string
+
+	// ServerCfg is copied to baseq3/server.cfg and executed on start
+	ServerCfg string
+
+	// RconPassword is set by a cfg file only the current user can read, which
+	// is removed when Run returns, so it never shows up in ps
+	RconPassword string
+
+	// LogFile receives the timestamped server output, appending across restarts
+	LogFile string
+
+	// Output optionally receives a copy of the timestamped output, like tee
+	Output io.Writer
+
+	// Lines optionally receives every timestamped line, to track the server
+	// in the same process
+	Lines chan<- string
+
+	// RestartDelay is the time to wait before restarting a crashed server
+	RestartDelay time.Duration
+
+	logger *slog.Logger
+}
+
+// NewLauncher creates a launcher for the ioquake3 installation in dir
+func NewLauncher(dir string, serverCfg string, logFile string, logger *slog.Logger) *Launcher {
+	return &Launcher{
+		Dir:          dir,
+		Binary:       DefaultBinary,
+		ServerCfg:    serverCfg,
+		LogFile:      logFile,
+		RestartDelay: defaultRestartDelay,
+		logger:       logger.With("component", "serve"),
+	}
+}
+
+// Validate checks that the ioquake3 installation and server.cfg exist
+func (l *Launcher) Validate() error {
+	if info, err := os.Stat(l.Dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("ioquake3 directory not found: %s", l.Dir)
+	}
+	if _, err := os.Stat(l.binaryPath()); err != nil {
+		return fmt.Errorf("server binary not found: %s", l.binaryPath())
+	}
+	if _, err := os.Stat(l.ServerCfg); err != nil {
+		return fmt.Errorf("server config not found: %s", l.ServerCfg)
+	}
+	if strings.ContainsAny(l.RconPassword, "\";\r\n") {
+		return fmt.Errorf("rcon password must not contain quotes, semicolons or line breaks")
+	}
+	return nil
+}
+
+func (l *Launcher) binaryPath() string {
+	return filepath.Join(l.Dir, l.Binary)
+}
+
+// Run starts the server and restarts it whenever it exits, until ctx is
+// cancelled. Lines is closed when Run returns.
+func (l *Launcher) Run(ctx context.Context) error {
+	if l.Lines != nil {
+		defer close(l.Lines)
+	}
+
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	if err := l.installServerCfg(); err != nil {
+		return err
+	}
+	if l.RconPassword != "" {
+		if err := l.installRconCfg(); err != nil {
+			return err
+		}
+		defer os.Remove(l.rconCfgPath())
+	}
+
+	logFile, err := os.OpenFile(l.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logFile.Close()
+
+	for {
+		start := time.Now()
+		err := l.runOnce(ctx, logFile)
+		if ctx.Err() != nil {
+			return nil
+		}
+		l.logger.Warn("Server exited, restarting",
+			"uptime", time.Since(start).Round(time.Second), "error", err, "delay", l.RestartDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(l.RestartDelay):
+		}
+	}
+}
+
+// installServerCfg copies server.cfg into the baseq3 directory
+func (l *Launcher) installServerCfg() error {
+	data, err := os.ReadFile(l.ServerCfg)
+	if err != nil {
+		return fmt.Errorf("failed to read server config: %w", err)
+	}
+	target := filepath.Join(l.Dir, "baseq3", "server.cfg")
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create baseq3 directory: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return fmt.Errorf("failed to install server config: %w", err)
+	}
+	return nil
+}
+
+func (l *Launcher) rconCfgPath() string {
+	return filepath.Join(l.Dir, "baseq3", rconCfg)
+}
+
+// installRconCfg writes the cfg setting the rcon password, readable by the
+// current user only
+func (l *Launcher) installRconCfg() error {
+	// WriteFile keeps the permissions of an existing file
+	if err := os.Remove(l.rconCfgPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to install rcon config: %w", err)
+	}
+	data := fmt.Sprintf("set rconpassword \"%s\"\n", l.RconPassword)
+	if err := os.WriteFile(l.rconCfgPath(), []byte(data), 0600); err != nil {
+		return fmt.Errorf("failed to install rcon config: %w", err)
+	}
+	return nil
+}
+
+// runOnce runs the server until it exits, timestamping every output line
+func (l *Launcher) runOnce(ctx context.Context, logFile io.Writer) error {
+	args := []string{"+exec", "server.cfg"}
+	if l.RconPassword != "" {
+		args = append([]string{"+exec", rconCfg}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, l.binaryPath(), args...)
+	cmd.Dir = l.Dir
+
+	// Let the server quit cleanly when ctx is cancelled, killing it only
+	// when it does not exit in time
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = shutdownTimeout
+
+	// Merge stderr into stdout like 2>&1
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	l.logger.Info("Starting server", "binary", l.binaryPath())
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		writer.Close()
+		done <- err
+	}()
+
+	for {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			l.writeLine(ctx, logFile, scanner.Text())
+		}
+		err := scanner.Err()
+		if err == nil {
+			break
+		}
+		// Start over after e.g. a line too long for the scanner, so the lines
+		// after it are still read and the server never blocks on a full pipe
+		l.logger.Warn("Failed to read server output, skipping", "error", err)
+	}
+
+	return <-done
+}
+
+// writeLine timestamps an output line and writes it to the log file and the
+// other destinations
+func (l *Launcher) writeLine(ctx context.Context, logFile io.Writer, text string) {
+	line := parser.AddTimestamp(text, time.Now())
+	if _, err := fmt.Fprintln(logFile, line); err != nil {
+		l.logger.Error("Failed to write log file", "error", err)
+	}
+	if l.Output != nil {
+		fmt.Fprintln(l.Output, line)
+	}
+	if l.Lines != nil {
+		select {
+		case l.Lines <- line:
+		case <-ctx.Done():
+		}
+	}
+}
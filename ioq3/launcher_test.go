@@ -0,0 +1,209 @@
+package ioq3
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// fakeServer writes a shell script standing in for ioq3ded that prints its
+// arguments and a kill, then exits as if it crashed
+func fakeServer(t *testing.T) (dir string, serverCfg string) {
+	t.Helper()
+
+	return fakeServerScript(t, "echo \"args: $*\"\necho 'Kill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN' >&2\nexit 1\n")
+}
+
+// fakeServerScript writes a shell script with the body standing in for ioq3ded
+func fakeServerScript(t *testing.T, body string) (dir string, serverCfg string) {
+	t.Helper()
+
+	dir = t.TempDir()
+	script := "#!/bin/sh\n" + body
+	if err := os.WriteFile(filepath.Join(dir, DefaultBinary), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	serverCfg = filepath.Join(t.TempDir(), "server.cfg")
+	if err := os.WriteFile(serverCfg, []byte("set g_gametype 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, serverCfg
+}
+
+func TestLauncher_Validate(t *testing.T) {
+	dir, serverCfg := fakeServer(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	if err := NewLauncher(dir, serverCfg, "game.log", logger).Validate(); err != nil {
+		t.Errorf("Expected valid launcher, got %v", err)
+	}
+	if err := NewLauncher(t.TempDir(), serverCfg, "game.log", logger).Validate(); err == nil {
+		t.Error("Expected error for missing server binary")
+	}
+	if err := NewLauncher(dir, filepath.Join(dir, "missing.cfg"), "game.log", logger).Validate(); err == nil {
+		t.Error("Expected error for missing server config")
+	}
+}
+
+func TestLauncher_RunTimestampsAndRestarts(t *testing.T) {
+	dir, serverCfg := fakeServer(t)
+	logFile := filepath.Join(t.TempDir(), "game.log")
+
+	launcher := NewLauncher(dir, serverCfg, logFile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	launcher.RconPassword = "secret"
+	launcher.RestartDelay = 10 * time.Millisecond
+	lines := make(chan string, 100)
+	launcher.Lines = lines
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- launcher.Run(ctx)
+	}()
+
+	// Wait for the server to have crashed and been restarted
+	var received []string
+	for len(received) < 4 {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for lines, got %v", received)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for _, line := range received {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 3 {
+			t.Fatalf("Expected timestamped line, got %q", line)
+		}
+		if _, err := time.Parse(models.TimestampLayout, fields[0]+" "+fields[1]); err != nil {
+			t.Errorf("Expected line to start with a timestamp, got %q", line)
+		}
+	}
+	if !strings.HasSuffix(received[0], "args: +exec deathquake_rcon.cfg +exec server.cfg") {
+		t.Errorf("Expected the rcon config to be executed first, got %q", received[0])
+	}
+	if !strings.HasSuffix(received[3], "PlayerOne killed PlayerTwo by MOD_RAILGUN") {
+		t.Errorf("Expected stderr output after restart, got %q", received[3])
+	}
+
+	// The log file keeps the output from before and after the restart
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(string(data), "MOD_RAILGUN"); count < 2 {
+		t.Errorf("Expected log file to contain both runs, got %d kills", count)
+	}
+
+	installed, err := os.ReadFile(filepath.Join(dir, "baseq3", "server.cfg"))
+	if err != nil || string(installed) != "set g_gametype 0\n" {
+		t.Errorf("Expected server.cfg to be installed, got %q (%v)", installed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "baseq3", rconCfg)); err == nil {
+		t.Error("Expected the rcon config to be removed when Run returns")
+	}
+}
+
+func TestLauncher_InstallRconCfg(t *testing.T) {
+	dir, serverCfg := fakeServer(t)
+	launcher := NewLauncher(dir, serverCfg, "game.log", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	launcher.RconPassword = "secret"
+
+	// A file left behind readable by others is replaced
+	path := filepath.Join(dir, "baseq3", rconCfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := launcher.installRconCfg(); err != nil {
+		t.Fatalf("installRconCfg failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected the rcon config to be readable by the owner only, got %o", perm)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "set rconpassword \"secret\"\n" {
+		t.Errorf("Unexpected rcon config %q (%v)", data, err)
+	}
+
+	launcher.RconPassword = "secret\"; quit"
+	if err := launcher.Validate(); err == nil {
+		t.Error("Expected error for a password that breaks out of the cfg")
+	}
+}
+
+func TestLauncher_RunSkipsLongLines(t *testing.T) {
+	dir, serverCfg := fakeServerScript(t, "head -c 100000 /dev/zero | tr '\\0' x\necho\necho 'Kill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN'\nexit 1\n")
+	launcher := NewLauncher(dir, serverCfg, filepath.Join(t.TempDir(), "game.log"), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	lines := make(chan string, 100)
+	launcher.Lines = lines
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go launcher.Run(ctx)
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if strings.HasSuffix(line, "MOD_RAILGUN") {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Timed out waiting for the line after the long line")
+		}
+	}
+}
+
+func TestLauncher_RunStopsServerWithSigterm(t *testing.T) {
+	dir, serverCfg := fakeServerScript(t, "trap 'echo terminated; exit 0' TERM\necho started\nwhile :; do sleep 0.05; done\n")
+	logFile := filepath.Join(t.TempDir(), "game.log")
+	launcher := NewLauncher(dir, serverCfg, logFile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	lines := make(chan string, 100)
+	launcher.Lines = lines
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- launcher.Run(ctx)
+	}()
+
+	select {
+	case <-lines:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the server to start")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "terminated") {
+		t.Errorf("Expected the server to quit on SIGTERM, got %q", data)
+	}
+}
@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// contentType is the Prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type playerMetrics struct {
+	name       string
+	score      float64
+	kills      int
+	deaths     int
+	roundKills int
+	rating     float64
+}
+
+// Exporter exports metrics derived from the game in the Prometheus text format
+type Exporter struct {
+	fileName string
+	logger   *slog.Logger
+
+	mu sync.Mutex
+
+	// Game metrics
+	players     []playerMetrics
+	weaponKills map[string]int
+	playerFrags map[string]int
+	rounds      int
+	ignored     int
+	isWarmup    bool
+	events      int
+
+	// Parser health
+	linesProcessed int
+	parseErrors    int
+	bytesRead      int64
+}
+
+// NewExporter creates an exporter for the game tailed from fileName
+// The file is used to calculate how far the parser is behind
+func NewExporter(fileName string, logger *slog.Logger) *Exporter {
+	return &Exporter{
+		fileName:    fileName,
+		logger:      logger.With("component", "metrics"),
+		weaponKills: make(map[string]int),
+		playerFrags: make(map[string]int),
+		isWarmup:    true,
+	}
+}
+
+// OnUpdate implements parser.Subscriber
+// Counters are taken from the new events in the game log, so they never decrease
+func (e *Exporter) OnUpdate(game *models.Snapshot) {
+	players := make([]playerMetrics, 0, len(game.Players))
+	for _, p := range game.Players {
+		players = append(players, playerMetrics{
+			name:       p.Name,
+			score:      p.Score,
+			kills:      p.Kills,
+			deaths:     p.Deaths,
+			roundKills: p.RoundKills,
+			rating:     p.Rating,
+		})
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, event := range game.Events[e.events:] {
+		if event.Type != models.EventKill {
+			continue
+		}
+		e.weaponKills[event.Weapon]++
+		if event.Attacker == event.Victim {
+			continue
+		}
+		if game.Player(event.Attacker) != nil {
+			e.playerFrags[event.Attacker]++
+		}
+	}
+	e.events = len(game.Events)
+
+	e.players = players
+	e.rounds = game.RoundsPlayed()
+	e.ignored = len(game.Rounds) - e.rounds
+	e.isWarmup = game.IsWarmup
+	e.linesProcessed = game.LinesProcessed
+	e.parseErrors = game.ParseErrors
+	e.bytesRead = game.BytesRead
+}
+
+// Handler returns the HTTP handler serving /metrics
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		e.WriteTo(w)
+	})
+	return mux
+}
+
+// ListenAndServe serves the metrics on the given address, e.g. ":9100"
+func (e *Exporter) ListenAndServe(addr string) error {
+	e.logger.Info("Serving metrics", "url", addr+"/metrics")
+	return http.ListenAndServe(addr, e.Handler())
+}
+
+// WriteTo writes all metrics in the Prometheus text format
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	lag := e.lag()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mw := &metricWriter{w: w}
+
+	mw.header("deathquake_player_score", "gauge", "Drinking score of the player")
+	for _, p := range e.players {
+		mw.sample("deathquake_player_score", p.score, "player", p.name)
+	}
+	mw.header("deathquake_player_kills", "gauge", "Kills of the player in saved rounds")
+	for _, p := range e.players {
+		mw.sample("deathquake_player_kills", float64(p.kills), "player", p.name)
+	}
+	mw.header("deathquake_player_deaths", "gauge", "Deaths of the player in saved rounds")
+	for _, p := range e.players {
+		mw.sample("deathquake_player_deaths", float64(p.deaths), "player", p.name)
+	}
+	mw.header("deathquake_player_round_kills", "gauge", "Kills of the player in the current round")
+	for _, p := range e.players {
+		mw.sample("deathquake_player_round_kills", float64(p.roundKills), "player", p.name)
+	}
+	mw.header("deathquake_player_rating", "gauge", "Skill rating of the player")
+	for _, p := range e.players {
+		mw.sample("deathquake_player_rating", p.rating, "player", p.name)
+	}
+
+	mw.header("deathquake_player_frags_total", "counter", "Frags made by the player outside warmup")
+	for _, name := range sortedKeys(e.playerFrags) {
+		mw.sample("deathquake_player_frags_total", float64(e.playerFrags[name]), "player", name)
+	}
+	mw.header("deathquake_weapon_kills_total", "counter", "Kills per weapon outside warmup, including suicides")
+	for _, weapon := range sortedKeys(e.weaponKills) {
+		mw.sample("deathquake_weapon_kills_total", float64(e.weaponKills[weapon]), "weapon", weapon)
+	}
+
+	mw.header("deathquake_rounds_total", "counter", "Saved rounds")
+	mw.sample("deathquake_rounds_total", float64(e.rounds))
+	mw.header("deathquake_rounds_ignored_total", "counter", "Rounds ignored rather than saved")
+	mw.sample("deathquake_rounds_ignored_total", float64(e.ignored))
+	mw.header("deathquake_warmup", "gauge", "1 while in warmup, 0 while a round is being played")
+	mw.sample("deathquake_warmup", boolToFloat(e.isWarmup))
+
+	mw.header("deathquake_parser_lines_total", "counter", "Log lines processed by the parser")
+	mw.sample("deathquake_parser_lines_total", float64(e.linesProcessed))
+	mw.header("deathquake_parser_errors_total", "counter", "Log lines that could not be parsed")
+	mw.sample("deathquake_parser_errors_total", float64(e.parseErrors))
+	mw.header("deathquake_parser_lag_bytes", "gauge", "Bytes in the log file not yet processed by the parser")
+	mw.sample("deathquake_parser_lag_bytes", float64(lag))
+
+	return mw.n, mw.err
+}
+
+// lag returns the number of bytes in the log file the parser has not read yet
+// The lag is unknown when reading from stdin, which has no file name
+func (e *Exporter) lag() int64 {
+	if e.fileName == "" {
+		return 0
+	}
+
+	info, err := os.Stat(e.fileName)
+	if err != nil {
+		e.logger.Warn("Failed to stat log file", "error", err)
+		return 0
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return max(info.Size()-e.bytesRead, 0)
+}
+
+// metricWriter writes the Prometheus text format, keeping the first error
+type metricWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (mw *metricWriter) printf(format string, args ...any) {
+	if mw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(mw.w, format, args...)
+	mw.n += int64(n)
+	mw.err = err
+}
+
+func (mw *metricWriter) header(name, metricType, help string) {
+	mw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// sample writes a sample with labels given as name/value pairs
+func (mw *metricWriter) sample(name string, value float64, labels ...string) {
+	if len(labels) == 0 {
+		mw.printf("%s %g\n", name, value)
+		return
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1])))
+	}
+	mw.printf("%s{%s} %g\n", name, strings.Join(pairs, ","), value)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
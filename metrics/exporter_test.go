@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+func send(exporter *Exporter, game *models.Game) {
+	exporter.OnUpdate(game.Snapshot())
+}
+
+func scrape(t *testing.T, exporter *Exporter) string {
+	t.Helper()
+
+	ts := httptest.NewServer(exporter.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Unexpected content type: %q", contentType)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func assertContains(t *testing.T, body string, lines ...string) {
+	t.Helper()
+
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
+
+func TestExporter_GameMetrics(t *testing.T) {
+	exporter := NewExporter(filepath.Join(t.TempDir(), "missing.log"), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}}
+	game := models.NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	game.NewMap("q3dm17", "2024-04-19 16:02:13")
+
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	send(exporter, game)
+	game.RecordKill("PlayerTwo", "PlayerTwo", "MOD_ROCKET_SPLASH")
+	game.RecordKill("<world>", "PlayerOne", "MOD_FALLING")
+	game.Save()
+	send(exporter, game)
+
+	body := scrape(t, exporter)
+	assertContains(t, body,
+		"# TYPE deathquake_weapon_kills_total counter",
+		`deathquake_weapon_kills_total{weapon="MOD_RAILGUN"} 2`,
+		`deathquake_weapon_kills_total{weapon="MOD_ROCKET_SPLASH"} 1`,
+		`deathquake_weapon_kills_total{weapon="MOD_FALLING"} 1`,
+		`deathquake_player_frags_total{player="PlayerOne"} 2`,
+		`deathquake_player_kills{player="PlayerOne"} 1`,
+		`deathquake_player_kills{player="PlayerTwo"} -1`,
+		`deathquake_player_score{player="PlayerOne"} 1`,
+		"deathquake_rounds_total 1",
+		"deathquake_rounds_ignored_total 0",
+		"deathquake_warmup 1",
+	)
+
+	// Suicides and world kills are not frags
+	if strings.Contains(body, `deathquake_player_frags_total{player="PlayerTwo"}`) {
+		t.Error("Expected suicides not to count as frags")
+	}
+	if strings.Contains(body, `player="<world>"`) {
+		t.Error("Expected ignored players not to be exported")
+	}
+}
+
+func TestExporter_ParserHealth(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "game.log")
+	if err := os.WriteFile(fileName, make([]byte, 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exporter := NewExporter(fileName, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.LinesProcessed = 12
+	game.ParseErrors = 3
+	game.BytesRead = 400
+	send(exporter, game)
+
+	assertContains(t, scrape(t, exporter),
+		"deathquake_parser_lines_total 12",
+		"deathquake_parser_errors_total 3",
+		"deathquake_parser_lag_bytes 600",
+	)
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "fjerlv", expected: "fjerlv"},
+		{value: `Say "hi"`, expected: `Say \"hi\"`},
+		{value: `back\slash`, expected: `back\\slash`},
+		{value: "new\nline", expected: `new\nline`},
+	}
+
+	for _, tt := range tests {
+		if result := escapeLabelValue(tt.value); result != tt.expected {
+			t.Errorf("escapeLabelValue(%q) = %q, want %q", tt.value, result, tt.expected)
+		}
+	}
+}
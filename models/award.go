@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Award is a title given to the player with the best result in a category
+type Award struct {
+	Title  string
+	Player string
+	Value  string
+}
+
+// String returns e.g. "Most Quads: PlayerOne (5)"
+func (a Award) String() string {
+	return fmt.Sprintf("%s: %s (%s)", a.Title, a.Player, a.Value)
+}
+
+// Awards returns the awards for the saved rounds
+// Categories nobody has scored in yet are left out
+func (g *Game) Awards() []Award {
+	players := make([]*Player, 0, len(g.Players))
+	for _, p := range g.Players {
+		if !g.IsHidden(p) {
+			players = append(players, p)
+		}
+	}
+	// Ties go to the first player alphabetically
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name < players[j].Name
+	})
+
+	var awards []Award
+	itemAward := func(title string, count func(p *Player) int) {
+		var best *Player
+		for _, p := range players {
+			if count(p) > 0 && (best == nil || count(p) > count(best)) {
+				best = p
+			}
+		}
+		if best != nil {
+			awards = append(awards, Award{Title: title, Player: best.Name, Value: fmt.Sprintf("%d", count(best))})
+		}
+	}
+
+	itemAward("Most Quads", func(p *Player) int { return p.Items[ItemQuad] })
+	itemAward("Most Mega Healths", func(p *Player) int { return p.Items[ItemMegaHealth] })
+	itemAward("Most Red Armors", func(p *Player) int { return p.Items[ItemRedArmor] })
+	itemAward("Weapon Collector", func(p *Player) int { return p.WeaponPickups() })
+
+	// The best single map control
+	var controller *RoundResult
+	var controlledMap string
+	for _, round := range g.Rounds {
+		if round.IsIgnored() {
+			continue
+		}
+		for i := range round.Results {
+			result := &round.Results[i]
+			if result.MajorItems > 0 && (controller == nil || result.Control > controller.Control) {
+				controller = result
+				controlledMap = round.MapName
+			}
+		}
+	}
+	if controller != nil {
+		awards = append(awards, Award{
+			Title:  "Map Controller",
+			Player: controller.Name,
+			Value:  fmt.Sprintf("%.0f%% on %s", controller.Control*100, controlledMap),
+		})
+	}
+
+	return awards
+}
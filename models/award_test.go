@@ -0,0 +1,70 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestAwards(t *testing.T) {
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2025-12-05 14:00:00")
+	game.NewMap("q3dm6", "2025-12-05 14:01:00")
+
+	game.RecordItem("PlayerOne", ItemQuad)
+	game.RecordItem("PlayerOne", ItemQuad)
+	game.RecordItem("PlayerOne", ItemRedArmor)
+	game.RecordItem("PlayerTwo", ItemMegaHealth)
+	game.RecordItem("PlayerTwo", "weapon_railgun")
+	game.RecordItem("PlayerTwo", "weapon_rocketlauncher")
+	game.RecordItem("PlayerTwo", "ammo_slugs")
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.RecordKill("PlayerTwo", "PlayerOne", "MOD_RAILGUN")
+	game.Save()
+
+	if quads := game.Players["PlayerOne"].Items[ItemQuad]; quads != 2 {
+		t.Errorf("Expected 2 quads to be committed, got %d", quads)
+	}
+	if game.Players["PlayerOne"].RoundItems != nil {
+		t.Error("Expected round items to be reset after saving")
+	}
+
+	result := game.Rounds[0].GetResult("PlayerOne")
+	if result.MajorItems != 3 || result.Control != 0.75 {
+		t.Errorf("Expected PlayerOne to control 3 of 4 major items, got %d and %.2f", result.MajorItems, result.Control)
+	}
+
+	expected := []Award{
+		{Title: "Most Quads", Player: "PlayerOne", Value: "2"},
+		{Title: "Most Mega Healths", Player: "PlayerTwo", Value: "1"},
+		{Title: "Most Red Armors", Player: "PlayerOne", Value: "1"},
+		{Title: "Weapon Collector", Player: "PlayerTwo", Value: "2"},
+		{Title: "Map Controller", Player: "PlayerOne", Value: "75% on q3dm6"},
+	}
+	awards := game.Awards()
+	if len(awards) != len(expected) {
+		t.Fatalf("Expected %d awards, got %v", len(expected), awards)
+	}
+	for i := range expected {
+		if awards[i] != expected[i] {
+			t.Errorf("Expected award %v, got %v", expected[i], awards[i])
+		}
+	}
+}
+
+func TestRecordItem_IgnoredDuringWarmup(t *testing.T) {
+	cfg := &config.Config{}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	game.RecordItem("PlayerOne", ItemQuad)
+
+	if len(game.Players) != 0 {
+		t.Errorf("Expected no players from warmup pickups, got %d", len(game.Players))
+	}
+	if awards := game.Awards(); len(awards) != 0 {
+		t.Errorf("Expected no awards, got %v", awards)
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import "github.com/fjerlv/deathquake-go/config"
+
+// botPolicy returns how bots are counted, see config.BotPolicyCount
+func (g *Game) botPolicy() string {
+	if g.Config == nil || g.Config.BotPolicy == "" {
+		return config.BotPolicyCount
+	}
+	return g.Config.BotPolicy
+}
+
+// botKillWeight returns what a kill against a bot is worth
+func (g *Game) botKillWeight() float64 {
+	if g.botPolicy() != config.BotPolicyReduce {
+		return 1
+	}
+	if g.Config.BotKillWeight <= 0 {
+		return config.DefaultBotKillWeight
+	}
+	return g.Config.BotKillWeight
+}
+
+// SetPlayerBot records whether a player is a bot, as seen in their userinfo
+func (g *Game) SetPlayerBot(playerName string, isBot bool) *Game {
+	if g.bots == nil {
+		g.bots = make(map[string]bool)
+	}
+	if g.bots[playerName] == isBot {
+		return g
+	}
+	g.bots[playerName] = isBot
+
+	if player, ok := g.Players[playerName]; ok {
+		g.applyBotPolicy(player)
+	}
+	return g
+}
+
+// applyBotPolicy marks the player as a bot, ignoring them when bots are excluded
+func (g *Game) applyBotPolicy(player *Player) {
+	player.IsBot = g.bots[player.Name]
+	if player.IsBot && g.botPolicy() == config.BotPolicyExclude {
+		g.debug("Bot marked as ignored", "player", player.Name)
+		player.SetIsIgnored(true)
+	}
+}
+
+// IsHidden returns true if the player is left out of the standings, either
+// ignored or a bot that is not counted as a regular player
+func (g *Game) IsHidden(player *Player) bool {
+	return player.IsIgnored || (player.IsBot && g.botPolicy() != config.BotPolicyCount)
+}
+
+// roundKillScore returns the round kills with kills against bots weighted
+func (g *Game) roundKillScore(player *Player) float64 {
+	return float64(player.RoundKills) - (1-g.botKillWeight())*float64(player.RoundBotKills)
+}
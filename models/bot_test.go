@@ -0,0 +1,108 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+// playBotRound plays a round where Human kills Sarge (a bot) twice and
+// Other once, and Sarge kills Other once
+func playBotRound(policy string) *Game {
+	cfg := &config.Config{BotPolicy: policy, BotKillWeight: 0.5}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.SetPlayerBot("Sarge", true)
+	game.NewMap("q3dm1", "2025-12-05 14:00:00")
+	game.NewMap("q3dm6", "2025-12-05 14:00:30")
+
+	game.RecordKill("Human", "Sarge", "MOD_RAILGUN")
+	game.RecordKill("Human", "Sarge", "MOD_RAILGUN")
+	game.RecordKill("Human", "Other", "MOD_RAILGUN")
+	game.RecordKill("Sarge", "Other", "MOD_RAILGUN")
+	game.Save()
+	return game
+}
+
+func sortedNames(game *Game) []string {
+	var names []string
+	for _, p := range game.GetSortedPlayers() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestBotPolicy_Count(t *testing.T) {
+	game := playBotRound(config.BotPolicyCount)
+
+	if len(game.GetSortedPlayers()) != 3 {
+		t.Errorf("Expected bots to be shown, got %v", sortedNames(game))
+	}
+	if game.Players["Human"].Kills != 3 || game.Players["Sarge"].Rank == 0 {
+		t.Errorf("Expected bots to count as regular players")
+	}
+}
+
+func TestBotPolicy_Exclude(t *testing.T) {
+	game := playBotRound(config.BotPolicyExclude)
+
+	if names := sortedNames(game); len(names) != 2 {
+		t.Errorf("Expected the bot to be left out, got %v", names)
+	}
+	if kills := game.Players["Human"].Kills; kills != 1 {
+		t.Errorf("Expected kills against bots not to count, got %d", kills)
+	}
+	if deaths := game.Players["Other"].Deaths; deaths != 1 {
+		t.Errorf("Expected deaths to bots not to count, got %d", deaths)
+	}
+}
+
+func TestBotPolicy_Hide(t *testing.T) {
+	game := playBotRound(config.BotPolicyHide)
+
+	if names := sortedNames(game); len(names) != 2 {
+		t.Errorf("Expected the bot to be hidden, got %v", names)
+	}
+	sarge := game.Players["Sarge"]
+	if sarge.Kills != 1 || sarge.Rank != 0 {
+		t.Errorf("Expected the bot to be tracked but not ranked, got %d kills and rank %d", sarge.Kills, sarge.Rank)
+	}
+	if game.Players["Human"].Rank != 1 || game.Players["Other"].Rank != 2 {
+		t.Errorf("Expected the players ranked without gaps")
+	}
+	if result := game.Rounds[0].GetResult("Sarge"); result != nil {
+		t.Errorf("Expected the bot to be left out of the round results, got %+v", result)
+	}
+	if !game.Players["Human"].IsRoundWinner() {
+		t.Errorf("Expected kills against bots to count fully, got %.2f", game.Players["Human"].Diff)
+	}
+}
+
+func TestBotPolicy_Reduce(t *testing.T) {
+	game := playBotRound(config.BotPolicyReduce)
+
+	human := game.Players["Human"]
+	if human.Kills != 3 || human.BotKills != 2 {
+		t.Errorf("Expected 3 kills with 2 against bots, got %d and %d", human.Kills, human.BotKills)
+	}
+
+	// 1 kill + 2 bot kills at half weight out of 3
+	if expected := 2.0 / 3.0; math.Abs(human.Diff-expected) > 1e-9 {
+		t.Errorf("Expected diff %.4f, got %.4f", expected, human.Diff)
+	}
+	if names := sortedNames(game); len(names) != 2 {
+		t.Errorf("Expected the bot to be hidden, got %v", names)
+	}
+}
+
+func TestSetPlayerBot_ExistingPlayer(t *testing.T) {
+	game := NewGame(&config.Config{BotPolicy: config.BotPolicyExclude}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.GetOrCreatePlayer("Sarge")
+	game.SetPlayerBot("Sarge", true)
+
+	if sarge := game.Players["Sarge"]; !sarge.IsBot || !sarge.IsIgnored {
+		t.Errorf("Expected an existing player seen as a bot to be excluded, got %+v", sarge)
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import "strings"
+
+// chatLogSize is the number of chat messages kept for display
+const chatLogSize = 10
+
+// ChatMessage is a message said in the game chat
+type ChatMessage struct {
+	Timestamp string
+	Player    string
+	Message   string
+	TeamOnly  bool
+}
+
+// RecordChat records a chat message, and runs it as a command when a judge
+// says something starting with "!"
+func (g *Game) RecordChat(playerName, message string, teamOnly bool) *Game {
+	g.debug("Chat message", "player", playerName, "message", message)
+
+	g.ChatLog = append(g.ChatLog, ChatMessage{
+		Timestamp: g.CurrentTime,
+		Player:    playerName,
+		Message:   message,
+		TeamOnly:  teamOnly,
+	})
+	if len(g.ChatLog) > chatLogSize {
+		g.ChatLog = g.ChatLog[len(g.ChatLog)-chatLogSize:]
+	}
+	g.addEvent(Event{Type: EventChat, Player: playerName, Message: message})
+
+	if strings.HasPrefix(message, commandPrefix) {
+		g.runCommand(playerName, message)
+	}
+	return g
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// commandPrefix starts a judge command in the chat, e.g. "!skip"
+const commandPrefix = "!"
+
+// IsJudge returns true if the player may run commands from the chat
+// Judges are matched by player name, which any player can change
+func (g *Game) IsJudge(playerName string) bool {
+	if g.Config == nil {
+		return false
+	}
+	for _, judge := range g.Config.Judges {
+		if judge == playerName {
+			return true
+		}
+	}
+	return false
+}
+
+// runCommand applies a command said in the chat to the live game
+//
+//	!skip          the current round is not saved
+//	!cider <name>  the player drinks cider from now on
+//	!beer <name>   the player drinks beer from now on
+//	!drank <name>  the player has drunk everything owed so far
+func (g *Game) runCommand(judge, message string) {
+	if !g.IsJudge(judge) {
+		g.debug("Ignoring command, not a judge", "player", judge, "command", message)
+		return
+	}
+
+	fields := strings.Fields(message)
+	command := strings.TrimPrefix(fields[0], commandPrefix)
+	argument := strings.Join(fields[1:], " ")
+
+	var result string
+	switch command {
+	case "skip":
+		result = g.skipRound()
+	case "cider", "beer", "drank":
+		player, err := g.findPlayer(argument)
+		if err != nil {
+			result = err.Error()
+			break
+		}
+		switch command {
+		case "cider":
+			player.SetDrinkingCider(true).RecalculateScore14()
+			result = fmt.Sprintf("%s drinks cider", player.Name)
+		case "beer":
+			player.SetDrinkingCider(false).RecalculateScore14()
+			result = fmt.Sprintf("%s drinks beer", player.Name)
+		case "drank":
+			player.SetDrank(player.Score)
+			result = fmt.Sprintf("%s has drunk %s", player.Name, player.Score14)
+		}
+	default:
+		result = fmt.Sprintf("unknown command %q", fields[0])
+	}
+
+	g.debug("Command run", "player", judge, "command", message, "result", result)
+	g.addEvent(Event{Type: EventCommand, Player: judge, Message: result})
+}
+
+// skipRound makes the current round be skipped instead of saved
+func (g *Game) skipRound() string {
+	if g.IsWarmup {
+		return "no round is being played"
+	}
+	g.skippedRounds = append(g.skippedRounds, g.CurrentRoundId)
+	return fmt.Sprintf("round on %s will be skipped", g.CurrentMapName)
+}
+
+// findPlayer finds a player by exact name, case insensitive name or unique
+// case insensitive prefix, so judges can type names quickly
+func (g *Game) findPlayer(name string) (*Player, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing player name")
+	}
+	if player, ok := g.Players[name]; ok && !player.IsIgnored {
+		return player, nil
+	}
+
+	var matches []*Player
+	for _, player := range g.Players {
+		if player.IsIgnored {
+			continue
+		}
+		if strings.EqualFold(player.Name, name) {
+			return player, nil
+		}
+		if strings.HasPrefix(strings.ToLower(player.Name), strings.ToLower(name)) {
+			matches = append(matches, player)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no player named %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%q matches %d players", name, len(matches))
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestCommands_JudgeAppliesToLiveGame(t *testing.T) {
+	cfg := &config.Config{Judges: []string{"Judge"}}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.CurrentTime = "2025-12-05 14:00:00"
+	game.NewMap("q3dm1", game.CurrentTime)
+	game.NewMap("q3dm6", game.CurrentTime)
+
+	game.RecordKill("Rysgaard", "Cramer", "MOD_RAILGUN")
+	game.RecordKill("Rysgaard", "Cramer", "MOD_RAILGUN")
+	game.RecordKill("Cramer", "Rysgaard", "MOD_RAILGUN")
+	game.Save()
+
+	// Not a judge
+	game.RecordChat("Cramer", "!cider Rysgaard", false)
+	if game.Players["Rysgaard"].IsDrinkingCider {
+		t.Error("Expected commands from players who are not judges to be ignored")
+	}
+
+	game.RecordChat("Judge", "!cider rys", false)
+	rysgaard := game.Players["Rysgaard"]
+	if !rysgaard.IsDrinkingCider || rysgaard.Score14 != "0.66 cider" {
+		t.Errorf("Expected Rysgaard to drink cider, got %v and %q", rysgaard.IsDrinkingCider, rysgaard.Score14)
+	}
+
+	game.RecordChat("Judge", "!drank Cramer", false)
+	cramer := game.Players["Cramer"]
+	if cramer.Drank != cramer.Score || cramer.Owed14() != "" {
+		t.Errorf("Expected Cramer to owe nothing, got %q", cramer.Owed14())
+	}
+
+	game.RecordChat("Judge", "!drank Nobody", false)
+	last := game.Events[len(game.Events)-1]
+	if last.Type != EventCommand || last.Message != `no player named "Nobody"` {
+		t.Errorf("Expected the command error as the last event, got %+v", last)
+	}
+
+	if len(game.ChatLog) != 4 {
+		t.Errorf("Expected 4 chat messages, got %d", len(game.ChatLog))
+	}
+}
+
+func TestCommands_SkipRound(t *testing.T) {
+	cfg := &config.Config{Judges: []string{"Judge"}}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.CurrentTime = "2025-12-05 14:00:00"
+
+	game.RecordChat("Judge", "!skip", false)
+	if last := game.Events[len(game.Events)-1]; last.Message != "no round is being played" {
+		t.Errorf("Expected skip to be refused during warmup, got %q", last.Message)
+	}
+
+	game.NewMap("q3dm1", game.CurrentTime)
+	game.NewMap("q3dm6", game.CurrentTime)
+	if game.IsSkipped() {
+		t.Fatal("Expected the round not to be skipped before the command")
+	}
+	game.RecordChat("Judge", "!skip", false)
+	if !game.IsSkipped() {
+		t.Error("Expected the round to be skipped after !skip")
+	}
+}
+
+func TestFindPlayer(t *testing.T) {
+	game := NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.GetOrCreatePlayer("Rysgaard")
+	game.GetOrCreatePlayer("Rasmus")
+
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{"Rysgaard", "Rysgaard", false},
+		{"rasmus", "Rasmus", false},
+		{"Ry", "Rysgaard", false},
+		{"R", "", true},
+		{"Cramer", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		player, err := game.findPlayer(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("findPlayer(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && player.Name != tt.expected {
+			t.Errorf("findPlayer(%q) = %s, expected %s", tt.name, player.Name, tt.expected)
+		}
+	}
+}
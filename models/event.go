@@ -0,0 +1,53 @@
+package models
+
+// Event types recorded in the game event log
+const (
+	EventMapChange  = "map_change"
+	EventKill       = "kill"
+	EventRoundSaved = "round_saved"
+
+	// A round that ended without counting, with the reason as message
+	EventRoundIgnored = "round_ignored"
+
+	EventFlagTaken    = "flag_taken"
+	EventFlagCaptured = "flag_captured"
+	EventFlagReturned = "flag_returned"
+
+	EventPlayerJoined = "player_joined"
+	EventPlayerLeft   = "player_left"
+
+	EventChat    = "chat"
+	EventCommand = "command"
+)
+
+// Event is an entry in the game event log
+// Only the fields relevant for the event type are set
+type Event struct {
+	Id        int
+	Type      string
+	Timestamp string
+	RoundId   string
+
+	// Map change
+	MapName string
+
+	// Kill
+	Attacker string
+	Victim   string
+	Weapon   string
+
+	// Flag, join, leave, chat and command
+	Player string
+	Team   string
+
+	// Chat message, the result of a command, or why a round was ignored
+	Message string
+}
+
+// addEvent appends an event to the event log, assigning the next id
+func (g *Game) addEvent(event Event) {
+	event.Id = len(g.Events) + 1
+	event.Timestamp = g.CurrentTime
+	event.RoundId = g.CurrentRoundId
+	g.Events = append(g.Events, event)
+}
@@ -1,10 +1,8 @@
 package models
 
 import (
-	"crypto/md5"
-	"encoding/hex"
 	"fmt"
-	"log"
+	"log/slog"
 	"sort"
 
 	"github.com/fjerlv/deathquake-go/config"
@@ -18,13 +16,59 @@ type Game struct {
 	Config *config.Config
 
 	// Logger
-	Logger *log.Logger
+	Logger *slog.Logger
 
 	// Game state
 	CurrentRoundId string // Will only be assigned for the next map
 	IsWarmup       bool
 	CurrentMapName string
 	MapChanges     int
+	CurrentTime    string // Timestamp of the last processed log line
+	GameType       int    // g_gametype of the current map
+
+	// Names of the connected clients by client number
+	clients map[int]string
+
+	// Time in the game of the clients that have begun, by client number
+	sessions map[int]*session
+
+	// Players seen as bots in their userinfo
+	bots map[string]bool
+
+	// Team games
+	teams            map[string]Team
+	flagCarriers     map[Team]string
+	pendingFlag      *flagPickup
+	ctfLogged        bool
+	RedScore         int
+	BlueScore        int
+	teamScoresLogged bool
+
+	// Most recent kills, oldest first
+	KillFeed []Kill
+
+	// Event log, oldest first
+	Events []Event
+
+	// Most recent chat messages, oldest first
+	ChatLog []ChatMessage
+
+	// Rounds skipped by a judge from the chat
+	skippedRounds []string
+
+	// Round history
+	Rounds            []*Round
+	CurrentRoundStart string
+
+	// Skill ratings carried across events
+	Ratings *Ratings
+
+	// Parser health
+	LinesProcessed   int
+	ParseErrors      int
+	ParseErrorCounts map[ParseErrorCategory]int
+	ParseErrorLog    []ParseError // Most recent lines that could not be parsed, oldest first
+	BytesRead        int64
 
 	// Maximum statistics tracking
 	MaxKills          int
@@ -40,13 +84,14 @@ type Game struct {
 // Constructor
 
 // NewGame creates and initializes a new Game instance
-func NewGame(cfg *config.Config, logger *log.Logger) *Game {
-	logger.Printf("[GAME] Initializing new game")
+func NewGame(cfg *config.Config, logger *slog.Logger) *Game {
+	logger.Debug("Initializing new game")
 	return &Game{
 		Players:  make(map[string]*Player),
 		Config:   cfg,
 		Logger:   logger,
 		IsWarmup: true,
+		Ratings:  NewRatings(),
 	}
 }
 
@@ -58,16 +103,21 @@ func (g *Game) GetOrCreatePlayer(playerName string) *Player {
 		return player
 	}
 
-	g.Logger.Printf("[%s] [PLAYER] Creating new player: %s", g.CurrentRoundId, playerName)
+	g.debug("Creating new player", "player", playerName)
 
 	newPlayer := &Player{
-		Name: playerName,
+		Name:   playerName,
+		Rating: InitialRating,
+		Team:   g.teams[playerName],
+	}
+	if g.Ratings != nil {
+		newPlayer.Rating = g.Ratings.Get(playerName)
 	}
 
 	for _, c := range g.Config.IgnoredPlayers {
 		if newPlayer.Name == c {
 			newPlayer.SetIsIgnored(true)
-			g.Logger.Printf("[%s] [PLAYER] Player %s marked as ignored", g.CurrentRoundId, playerName)
+			g.debug("Player marked as ignored", "player", playerName)
 			break
 		}
 	}
@@ -75,16 +125,32 @@ func (g *Game) GetOrCreatePlayer(playerName string) *Player {
 	for _, c := range g.Config.DrinkingCiderPlayers {
 		if newPlayer.Name == c {
 			newPlayer.SetDrinkingCider(true)
-			g.Logger.Printf("[%s] [PLAYER] Player %s marked as drinking cider", g.CurrentRoundId, playerName)
+			g.debug("Player marked as drinking cider", "player", playerName)
 			break
 		}
 	}
 
+	g.applyBotPolicy(newPlayer)
+
 	g.Players[playerName] = newPlayer
 	return newPlayer
 }
 
-// GetSortedPlayers returns non-ignored players sorted for UI display
+// SetClientName records the name of the player using a client number
+func (g *Game) SetClientName(clientId int, playerName string) *Game {
+	if g.clients == nil {
+		g.clients = make(map[int]string)
+	}
+	g.clients[clientId] = playerName
+	return g
+}
+
+// ClientName returns the name of the player using a client number, or "" if unknown
+func (g *Game) ClientName(clientId int) string {
+	return g.clients[clientId]
+}
+
+// GetSortedPlayers returns the players that are not hidden sorted for UI display
 // Sorting priority:
 // 1. Ranked players before unranked (rank 0 means not yet ranked)
 // 2. Lower rank number first (1st place before 2nd place)
@@ -93,7 +159,7 @@ func (g *Game) GetOrCreatePlayer(playerName string) *Player {
 func (g *Game) GetSortedPlayers() []*Player {
 	playersAsSlice := make([]*Player, 0, len(g.Players))
 	for _, player := range g.Players {
-		if player.IsIgnored {
+		if g.IsHidden(player) {
 			continue
 		}
 		playersAsSlice = append(playersAsSlice, player)
@@ -142,25 +208,31 @@ func (g *Game) GetFragLimit() int {
 // NewMap updates the map and handles warmup state transitions
 func (g *Game) NewMap(newMapName string, timestamp string) *Game {
 	if newMapName != g.CurrentMapName {
-		g.Logger.Printf("[%s] [MAP] Changing map from '%s' to '%s'", g.CurrentRoundId, g.CurrentMapName, newMapName)
+		// The round being played ended without an Exit, e.g. by an rcon map change
+		if !g.IsWarmup && g.roundPlayed() {
+			g.EndRound(false)
+		}
+
+		g.debug("Changing map", "from", g.CurrentMapName, "map", newMapName)
 		g.CurrentMapName = newMapName
 		g.MapChanges++
 
-		hash := md5.Sum([]byte(timestamp))
-		g.CurrentRoundId = hex.EncodeToString(hash[:])
-		g.Logger.Printf("[%s] [MAP] Round ID generated: %s", g.CurrentRoundId, g.CurrentRoundId)
+		g.CurrentRoundId = RoundId(timestamp, newMapName)
+		g.CurrentRoundStart = timestamp
+		g.debug("Round ID generated", "map", newMapName)
+		g.addEvent(Event{Type: EventMapChange, MapName: newMapName})
 
 		// After first map change, warmup is over
 		if g.MapChanges > 1 {
 			g.IsWarmup = false
-			g.Logger.Printf("[%s] [MAP] Warmup ended, game is now active", g.CurrentRoundId)
+			g.debug("Warmup ended, game is now active", "map", newMapName)
 		} else {
-			g.Logger.Printf("[%s] [MAP] First map change, still in warmup", g.CurrentRoundId)
+			g.debug("First map change, still in warmup", "map", newMapName)
 		}
 	}
 
 	// Discard round variables for all players
-	g.Logger.Printf("[%s] [MAP] Discarding round stats for %d players", g.CurrentRoundId, len(g.Players))
+	g.debug("Discarding round stats", "players", len(g.Players))
 	for _, p := range g.Players {
 		p.DiscardRound()
 	}
@@ -172,28 +244,47 @@ func (g *Game) NewMap(newMapName string, timestamp string) *Game {
 // Handles world kills, suicides, and normal kills with weapon tracking
 func (g *Game) RecordKill(attackerName, victimName, weapon string) *Game {
 	if g.IsWarmup {
-		g.Logger.Printf("[%s] [KILL] Ignoring kill during warmup: %s killed %s", g.CurrentRoundId, attackerName, victimName)
+		g.debug("Ignoring kill during warmup", "player", attackerName, "victim", victimName, "weapon", weapon)
 		return g
 	}
 
 	attacker := g.GetOrCreatePlayer(attackerName)
 	victim := g.GetOrCreatePlayer(victimName)
+	if (attacker.IsBot || victim.IsBot) && g.botPolicy() == config.BotPolicyExclude {
+		g.debug("Ignoring kill involving a bot", "player", attackerName, "victim", victimName, "weapon", weapon)
+		return g
+	}
+	g.dropFlag(victimName)
+
+	g.KillFeed = append(g.KillFeed, Kill{
+		Timestamp: g.CurrentTime,
+		Attacker:  attackerName,
+		Victim:    victimName,
+		Weapon:    weapon,
+	})
+	if len(g.KillFeed) > killFeedSize {
+		g.KillFeed = g.KillFeed[len(g.KillFeed)-killFeedSize:]
+	}
+	g.addEvent(Event{Type: EventKill, Attacker: attackerName, Victim: victimName, Weapon: weapon})
 
 	if attacker.Name == "<world>" || attacker.Name == victim.Name {
 		// World kills and suicides both penalize the victim/player
 		if attacker.Name == "<world>" {
-			g.Logger.Printf("[%s] [KILL] World kill: %s died to environment", g.CurrentRoundId, victimName)
+			g.debug("World kill", "victim", victimName, "weapon", weapon)
 		} else {
-			g.Logger.Printf("[%s] [KILL] Suicide: %s killed themselves", g.CurrentRoundId, victimName)
+			g.debug("Suicide", "victim", victimName, "weapon", weapon)
 		}
 		victim.SubtractKills()
 		victim.IncrementDeaths()
 		victim.IncrementSuicideDeaths()
 	} else {
 		// Normal kill
-		g.Logger.Printf("[%s] [KILL] %s killed %s with %s", g.CurrentRoundId, attackerName, victimName, weapon)
+		g.debug("Kill", "player", attackerName, "victim", victimName, "weapon", weapon)
 		attacker.IncrementKills()
 		victim.IncrementDeaths()
+		if victim.IsBot {
+			attacker.IncrementBotKills()
+		}
 
 		// Track weapon-specific kills
 		switch weapon {
@@ -210,17 +301,48 @@ func (g *Game) RecordKill(attackerName, victimName, weapon string) *Game {
 
 // Save saves the current round for all players
 func (g *Game) Save() *Game {
-	g.Logger.Printf("[%s] [SAVE] Saving round results", g.CurrentRoundId)
+	g.debug("Saving round results", "map", g.CurrentMapName)
 
 	fragLimit := g.GetFragLimit()
-	g.Logger.Printf("[%s] [SAVE] Frag limit for this round: %d", g.CurrentRoundId, fragLimit)
+	g.debug("Frag limit for this round", "frag_limit", fragLimit)
+
+	g.countSessions()
+	participants := g.getRoundParticipants()
+	round := g.newRound(participants)
+	g.updateRatings(participants, round.Key())
+
+	if g.IsTeamGame() {
+		// Team games: every player drinks the team's share
+		scores := g.TeamRoundScores()
+		diffs := g.teamRoundDiffs(g.teamDrinkScores(scores))
+		round.RedScore = scores[TeamRed]
+		round.BlueScore = scores[TeamBlue]
+		g.debug("Team round",
+			"red", scores[TeamRed], "red_diff", diffs[TeamRed], "blue", scores[TeamBlue], "blue_diff", diffs[TeamBlue])
+		for _, p := range g.Players {
+			p.SaveRoundDiff(diffs[p.Team])
+		}
+		g.teamScoresLogged = false
+	} else {
+		for _, p := range g.Players {
+			p.SaveRoundDiff(g.roundKillScore(p) / float64(fragLimit))
+		}
+	}
 
-	for _, p := range g.Players {
-		p.SaveRound(fragLimit)
+	for i := range round.Results {
+		p := g.Players[round.Results[i].Name]
+		round.Results[i].Diff = p.Diff
+		round.Results[i].Rating = p.Rating
+		round.Results[i].RatingDiff = p.RatingDiff
 	}
+	g.Rounds = append(g.Rounds, round)
+	g.addEvent(Event{Type: EventRoundSaved, MapName: round.MapName})
 
 	playerSlice := make([]*Player, 0, len(g.Players))
 	for _, p := range g.Players {
+		if p.IsBot && g.IsHidden(p) {
+			continue
+		}
 		playerSlice = append(playerSlice, p)
 	}
 
@@ -249,19 +371,18 @@ func (g *Game) Save() *Game {
 	})
 
 	// Assign sequential ranks (1, 2, 3, ...)
-	g.Logger.Printf("[%s] [SAVE] Assigning ranks to %d players", g.CurrentRoundId, len(playerSlice))
+	g.debug("Assigning ranks", "players", len(playerSlice))
 	for i, player := range playerSlice {
 		player.SetRank(i + 1)
-		g.Logger.Printf("[%s] [RANK] #%d: %s (score: %.2f, kills: %d)",
-			g.CurrentRoundId, i+1, player.Name, player.Score, player.Kills)
+		g.debug("Rank", "rank", i+1, "player", player.Name, "score", player.Score, "kills", player.Kills)
 	}
 
 	// Update the max values for the whole game
-	g.Logger.Printf("[%s] [SAVE] Updating maximum statistics", g.CurrentRoundId)
+	g.debug("Updating maximum statistics")
 	g.MaxKillDeathRatio = 0
 	g.MaxKills = 0
 	for _, p := range g.Players {
-		if p.IsIgnored {
+		if g.IsHidden(p) {
 			continue
 		}
 		g.MaxKills = max(g.MaxKills, p.Kills)
@@ -275,21 +396,104 @@ func (g *Game) Save() *Game {
 	}
 
 	g.IsWarmup = true
-	g.Logger.Printf("[%s] [SAVE] Round saved, entering warmup mode", g.CurrentRoundId)
+	g.debug("Round saved, entering warmup mode", "map", g.CurrentMapName)
 	return g
 }
 
-// Utility Functions
+// getRoundParticipants returns the players that are not hidden and killed or died
+// in the current round, sorted by round kills (highest first)
+func (g *Game) getRoundParticipants() []*Player {
+	participants := make([]*Player, 0, len(g.Players))
+	for _, p := range g.Players {
+		if g.IsHidden(p) || (p.RoundKills == 0 && p.RoundDeaths == 0) {
+			continue
+		}
+		participants = append(participants, p)
+	}
 
-// IsSkipped returns true if the game's hash is in the skip list
-func (g *Game) IsSkipped() bool {
-	for _, gameId := range g.Config.IgnoredRounds {
-		if g.CurrentRoundId == gameId {
-			g.Logger.Printf("[%s] [SKIP] Round is in ignored list, skipping", g.CurrentRoundId)
-			return true
+	sort.Slice(participants, func(i, j int) bool {
+		if participants[i].RoundKills != participants[j].RoundKills {
+			return participants[i].RoundKills > participants[j].RoundKills
 		}
+		return participants[i].Name < participants[j].Name
+	})
+
+	return participants
+}
+
+// updateRatings applies the rating changes from the current round and
+// persists them when a ratings file is configured
+// roundKey tells the round apart from rounds of other logs with the same id
+func (g *Game) updateRatings(participants []*Player, roundKey string) {
+	// Players sitting out the round keep their rating without a trend
+	for _, p := range g.Players {
+		p.UpdateRating(0)
+	}
+
+	// Players are seeded with ratings that already include the round when
+	// the log is read again, e.g. after a restart
+	if g.Ratings != nil && g.Ratings.IsApplied(roundKey) {
+		g.debug("Ratings already include the round, not applying it again")
+		return
 	}
-	return false
+
+	diffs := calculateRatingDiffs(participants)
+	for _, p := range participants {
+		p.UpdateRating(diffs[p.Name])
+		g.debug("Rating updated", "player", p.Name, "rating", p.Rating, "diff", p.RatingDiff)
+		if g.Ratings != nil {
+			g.Ratings.Set(p.Name, p.Rating)
+		}
+	}
+	if g.Ratings != nil {
+		g.Ratings.MarkApplied(roundKey)
+	}
+
+	if g.Ratings == nil || g.Config == nil || g.Config.RatingsFile == "" {
+		return
+	}
+	if err := g.Ratings.SaveToFile(g.Config.RatingsFile); err != nil {
+		g.warn("Failed to save ratings", "error", err)
+	}
+}
+
+// newRound creates the history entry for the current round
+// Must be called before the round stats are committed, Save fills in the
+// ratings after applying the round
+func (g *Game) newRound(participants []*Player) *Round {
+	round := &Round{
+		Id:        g.CurrentRoundId,
+		LegacyId:  LegacyRoundId(g.CurrentRoundStart),
+		MapName:   g.CurrentMapName,
+		StartedAt: g.CurrentRoundStart,
+		EndedAt:   g.CurrentTime,
+		GameType:  g.GameType,
+		Results:   make([]RoundResult, 0, len(participants)),
+	}
+	control := g.roundControl()
+	for _, p := range participants {
+		round.Results = append(round.Results, RoundResult{
+			Name:       p.Name,
+			Team:       p.Team.String(),
+			Kills:      p.RoundKills,
+			Deaths:     p.RoundDeaths,
+			Rating:     p.Rating,
+			RatingDiff: p.RatingDiff,
+			MajorItems: p.RoundMajorItems(),
+			Control:    control[p.Name],
+			TimePlayed: p.RoundTimePlayed,
+			JoinedLate: p.RoundJoinedLate,
+		})
+	}
+	return round
+}
+
+// Utility Functions
+
+// IsSkipped returns true if the round's id or legacy hash is in the skip list,
+// or a judge skipped the round
+func (g *Game) IsSkipped() bool {
+	return g.skipReason() != ""
 }
 
 // Print returns a formatted string with game information for logging
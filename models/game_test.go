@@ -1,8 +1,9 @@
 package models
 
 import (
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"testing"
 
 	"github.com/fjerlv/deathquake-go/config"
@@ -13,7 +14,7 @@ func TestNewGame(t *testing.T) {
 		IgnoredPlayers:       []string{"<world>"},
 		DrinkingCiderPlayers: []string{"TestPlayer"},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := NewGame(cfg, logger)
 
 	// Verify Players map is initialized
@@ -106,7 +107,7 @@ func TestChangeMap(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			logger := log.New(io.Discard, "", 0)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 			game := &Game{
 				CurrentMapName: tt.initialMap,
 				MapChanges:     tt.initialMapChanges,
@@ -130,7 +131,7 @@ func TestChangeMap(t *testing.T) {
 }
 
 func TestSetIsWarmup(t *testing.T) {
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &Game{IsWarmup: false, Logger: logger}
 
 	game.IsWarmup = true
@@ -143,3 +144,29 @@ func TestSetIsWarmup(t *testing.T) {
 		t.Error("Expected IsWarmup to be false")
 	}
 }
+
+func TestRecordKill_KillFeed(t *testing.T) {
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := NewGame(cfg, logger)
+
+	// Warmup kills are not part of the feed
+	game.RecordKill("A", "B", "MOD_RAILGUN")
+	if len(game.KillFeed) != 0 {
+		t.Errorf("Expected empty kill feed during warmup, got %d kills", len(game.KillFeed))
+	}
+
+	game.IsWarmup = false
+	for i := 0; i < killFeedSize+5; i++ {
+		game.CurrentTime = fmt.Sprintf("2024-04-19 16:10:%02d", i)
+		game.RecordKill("A", "B", "MOD_RAILGUN")
+	}
+
+	if len(game.KillFeed) != killFeedSize {
+		t.Fatalf("Expected kill feed to be capped at %d, got %d", killFeedSize, len(game.KillFeed))
+	}
+	last := game.KillFeed[len(game.KillFeed)-1]
+	if last.Timestamp != "2024-04-19 16:10:14" || last.Attacker != "A" || last.Victim != "B" {
+		t.Errorf("Unexpected latest kill: %+v", last)
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Reasons for ignoring a round other than the configured rules
+const (
+	ignoreReasonJudge     = "skipped by a judge"
+	ignoreReasonListed    = "in ignored_rounds"
+	ignoreReasonMapChange = "ended by a map change rather than Exit"
+)
+
+// EndRound ends the round being played, saving it unless it is ignored
+// Ignored rounds are kept in the round history with the reason
+// endedByExit is false when the map changed before the round ended
+func (g *Game) EndRound(endedByExit bool) *Game {
+	round := g.newRound(g.getRoundParticipants())
+	reason := g.ignoreReason(round, endedByExit)
+	if reason == "" {
+		return g.Save()
+	}
+
+	g.debug("Round ignored", "map", round.MapName, "reason", reason)
+	round.IgnoredReason = reason
+	for i := range round.Results {
+		round.Results[i].RatingDiff = 0
+	}
+	g.Rounds = append(g.Rounds, round)
+	g.addEvent(Event{Type: EventRoundIgnored, MapName: round.MapName, Message: reason})
+	g.IsWarmup = true
+	return g
+}
+
+// skipReason returns why the round is skipped by its id, or "" if it isn't
+func (g *Game) skipReason() string {
+	if slices.Contains(g.skippedRounds, g.CurrentRoundId) {
+		return ignoreReasonJudge
+	}
+	legacyId := LegacyRoundId(g.CurrentRoundStart)
+	for _, id := range g.Config.IgnoredRounds {
+		if id == g.CurrentRoundId || id == legacyId {
+			return ignoreReasonListed
+		}
+	}
+	return ""
+}
+
+// ignoreReason returns why the round is ignored, or "" if it counts
+func (g *Game) ignoreReason(round *Round, endedByExit bool) string {
+	if reason := g.skipReason(); reason != "" {
+		return reason
+	}
+
+	rules := g.Config.IgnoreRules
+	if !endedByExit && rules.IgnoresMapChanges() {
+		return ignoreReasonMapChange
+	}
+	if slices.Contains(rules.Maps, round.MapName) {
+		return "on map " + round.MapName
+	}
+	if start, ok := parseTimestamp(round.StartedAt); ok {
+		for _, period := range rules.Periods {
+			if period.Contains(start) {
+				return fmt.Sprintf("started between %s and %s", period.From, period.To)
+			}
+		}
+	}
+	if rules.MinPlayers > 0 && len(round.Results) < rules.MinPlayers {
+		return fmt.Sprintf("fewer than %d players", rules.MinPlayers)
+	}
+	minDuration := time.Duration(rules.MinDurationSeconds) * time.Second
+	if duration, ok := round.Duration(); ok && duration < minDuration {
+		return fmt.Sprintf("shorter than %s", minDuration)
+	}
+	return ""
+}
+
+// roundPlayed returns true if anyone killed or died in the round
+func (g *Game) roundPlayed() bool {
+	return len(g.getRoundParticipants()) > 0
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+// playRound plays a round on the map from start to end with the players
+func playRound(game *Game, mapName, start, end string, players ...string) {
+	game.CurrentTime = start
+	game.NewMap(mapName, start)
+	for i := 1; i < len(players); i++ {
+		game.RecordKill(players[0], players[i], "MOD_RAILGUN")
+	}
+	game.CurrentTime = end
+}
+
+func TestEndRound_IgnoreRules(t *testing.T) {
+	rules := config.IgnoreRules{
+		MinDurationSeconds: 120,
+		MinPlayers:         3,
+		Periods:            []config.Period{{From: "18:00", To: "18:30"}},
+		Maps:               []string{"q3dm17"},
+	}
+	tests := []struct {
+		name    string
+		mapName string
+		start   string
+		end     string
+		reason  string
+	}{
+		{"counted", "q3dm6", "2024-04-19 16:02:13", "2024-04-19 16:12:13", ""},
+		{"short", "q3dm6", "2024-04-19 16:02:13", "2024-04-19 16:03:13", "shorter than 2m0s"},
+		{"period", "q3dm6", "2024-04-19 18:10:00", "2024-04-19 18:20:00", "started between 18:00 and 18:30"},
+		{"map", "q3dm17", "2024-04-19 16:02:13", "2024-04-19 16:12:13", "on map q3dm17"},
+	}
+	for _, tt := range tests {
+		game := NewGame(&config.Config{IgnoreRules: rules}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		game.NewMap("q3dm1", "2024-04-19 16:01:17")
+		playRound(game, tt.mapName, tt.start, tt.end, "PlayerOne", "PlayerTwo", "PlayerThree")
+		game.EndRound(true)
+
+		if len(game.Rounds) != 1 {
+			t.Fatalf("%s: expected the round in the history, got %d rounds", tt.name, len(game.Rounds))
+		}
+		if reason := game.Rounds[0].IgnoredReason; reason != tt.reason {
+			t.Errorf("%s: expected reason %q, got %q", tt.name, tt.reason, reason)
+		}
+		if counted := game.Players["PlayerOne"].Kills > 0; counted == (tt.reason != "") {
+			t.Errorf("%s: expected the kills to be counted only when the round counts", tt.name)
+		}
+		if !game.IsWarmup {
+			t.Errorf("%s: expected warmup after the round ended", tt.name)
+		}
+	}
+
+	game := NewGame(&config.Config{IgnoreRules: rules}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	playRound(game, "q3dm6", "2024-04-19 16:02:13", "2024-04-19 16:12:13", "PlayerOne", "PlayerTwo")
+	game.EndRound(true)
+	if reason := game.Rounds[0].IgnoredReason; reason != "fewer than 3 players" {
+		t.Errorf("Expected the round with 2 players to be ignored, got %q", reason)
+	}
+	last := game.Events[len(game.Events)-1]
+	if last.Type != EventRoundIgnored || last.Message != "fewer than 3 players" {
+		t.Errorf("Expected a round ignored event with the reason, got %+v", last)
+	}
+	if played := game.Snapshot().RoundsPlayed(); played != 0 {
+		t.Errorf("Expected no rounds played, got %d", played)
+	}
+}
+
+func TestEndRound_IgnoredRoundsAndJudges(t *testing.T) {
+	cfg := &config.Config{IgnoredRounds: []string{"2024-04-19T16:02:13-q3dm6"}}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	playRound(game, "q3dm6", "2024-04-19 16:02:13", "2024-04-19 16:12:13", "PlayerOne", "PlayerTwo")
+	game.EndRound(true)
+	if reason := game.Rounds[0].IgnoredReason; reason != "in ignored_rounds" {
+		t.Errorf("Expected the listed round to be ignored, got %q", reason)
+	}
+
+	cfg = &config.Config{Judges: []string{"Judge"}}
+	game = NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	playRound(game, "q3dm6", "2024-04-19 16:02:13", "2024-04-19 16:12:13", "PlayerOne", "PlayerTwo")
+	game.RecordChat("Judge", "!skip", false)
+	game.EndRound(true)
+	if reason := game.Rounds[0].IgnoredReason; reason != "skipped by a judge" {
+		t.Errorf("Expected the skipped round to be ignored, got %q", reason)
+	}
+}
+
+func TestNewMap_IgnoresRoundEndedByMapChange(t *testing.T) {
+	game := NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	playRound(game, "q3dm6", "2024-04-19 16:02:13", "2024-04-19 16:12:13", "PlayerOne", "PlayerTwo")
+	game.NewMap("q3dm7", "2024-04-19 16:12:13")
+
+	if len(game.Rounds) != 1 || game.Rounds[0].IgnoredReason != ignoreReasonMapChange {
+		t.Fatalf("Expected the round ended by the map change to be ignored, got %+v", game.Rounds)
+	}
+	if game.Players["PlayerOne"].Kills != 0 {
+		t.Errorf("Expected the kills of the ignored round not to count")
+	}
+}
+
+func TestNewMap_CountsRoundEndedByMapChange(t *testing.T) {
+	endedByMapChange := false
+	cfg := &config.Config{IgnoreRules: config.IgnoreRules{EndedByMapChange: &endedByMapChange}}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	playRound(game, "q3dm6", "2024-04-19 16:02:13", "2024-04-19 16:12:13", "PlayerOne", "PlayerTwo")
+	game.NewMap("q3dm7", "2024-04-19 16:12:13")
+
+	if len(game.Rounds) != 1 || game.Rounds[0].IsIgnored() {
+		t.Fatalf("Expected the round ended by the map change to count, got %+v", game.Rounds)
+	}
+	if game.Players["PlayerOne"].Kills != 1 {
+		t.Errorf("Expected PlayerOne to have 1 kill, got %d", game.Players["PlayerOne"].Kills)
+	}
+}
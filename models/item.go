@@ -0,0 +1,61 @@
+package models
+
+import "strings"
+
+// Item class names from Item: lines
+const (
+	ItemMegaHealth = "item_health_mega"
+	ItemRedArmor   = "item_armor_body"
+	ItemQuad       = "item_quad"
+)
+
+// majorItems are the power items that decide who controls a map
+var majorItems = []string{ItemMegaHealth, ItemRedArmor, ItemQuad}
+
+// IsMajorItem returns true for the items that count towards map control
+func IsMajorItem(item string) bool {
+	for _, major := range majorItems {
+		if item == major {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWeapon returns true for weapon pickups, e.g. weapon_railgun
+func IsWeapon(item string) bool {
+	return strings.HasPrefix(item, "weapon_")
+}
+
+// RecordItem records a player picking up an item
+func (g *Game) RecordItem(playerName string, item string) *Game {
+	if g.IsWarmup {
+		return g
+	}
+
+	g.debug("Item picked up", "player", playerName, "item", item)
+	g.GetOrCreatePlayer(playerName).IncrementItem(item)
+	return g
+}
+
+// roundControl returns each player's share of the major items picked up in
+// the current round
+func (g *Game) roundControl() map[string]float64 {
+	total := 0
+	for _, p := range g.Players {
+		if !p.IsIgnored {
+			total += p.RoundMajorItems()
+		}
+	}
+
+	control := make(map[string]float64)
+	if total == 0 {
+		return control
+	}
+	for _, p := range g.Players {
+		if !p.IsIgnored {
+			control[p.Name] = float64(p.RoundMajorItems()) / float64(total)
+		}
+	}
+	return control
+}
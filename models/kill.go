@@ -0,0 +1,12 @@
+package models
+
+// killFeedSize is the number of recent kills kept in the kill feed
+const killFeedSize = 10
+
+// Kill is a single kill in the kill feed
+type Kill struct {
+	Timestamp string
+	Attacker  string
+	Victim    string
+	Weapon    string
+}
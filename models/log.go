@@ -0,0 +1,34 @@
+package models
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// debug logs a debug message with the current round id
+func (g *Game) debug(msg string, args ...any) {
+	g.log(slog.LevelDebug, msg, args...)
+}
+
+// warn logs a warning with the current round id
+func (g *Game) warn(msg string, args ...any) {
+	g.log(slog.LevelWarn, msg, args...)
+}
+
+// log adds the round id to the message, keeping the source of the caller
+func (g *Game) log(level slog.Level, msg string, args ...any) {
+	ctx := context.Background()
+	if !g.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	// Skip runtime.Callers, log and debug or warn
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add("round_id", g.CurrentRoundId)
+	record.Add(args...)
+	_ = g.Logger.Handler().Handle(ctx, record)
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestGame_LogsFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug}))
+	game := NewGame(&config.Config{}, logger)
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	game.NewMap("q3dm17", "2024-04-19 16:02:13")
+
+	buf.Reset()
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+
+	var kill map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		if record["msg"] == "Kill" {
+			kill = record
+		}
+	}
+	if kill == nil {
+		t.Fatalf("Expected the kill to be logged, got %q", buf.String())
+	}
+
+	expected := map[string]any{
+		"level":    "DEBUG",
+		"round_id": game.CurrentRoundId,
+		"player":   "PlayerOne",
+		"victim":   "PlayerTwo",
+		"weapon":   "MOD_RAILGUN",
+	}
+	for key, value := range expected {
+		if kill[key] != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, kill[key])
+		}
+	}
+	source, _ := kill["source"].(map[string]any)
+	if file, _ := source["file"].(string); filepath.Base(file) != "game.go" {
+		t.Errorf("Expected the source to be the caller in game.go, got %v", source)
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parseErrorLogSize is the number of unparsed lines kept for display
+const parseErrorLogSize = 50
+
+// ParseErrorCategory is the kind of log line that could not be parsed
+type ParseErrorCategory string
+
+const (
+	ParseErrorMalformed  ParseErrorCategory = "malformed"
+	ParseErrorKill       ParseErrorCategory = "kill"
+	ParseErrorUserinfo   ParseErrorCategory = "userinfo"
+	ParseErrorClient     ParseErrorCategory = "client"
+	ParseErrorItem       ParseErrorCategory = "item"
+	ParseErrorCTF        ParseErrorCategory = "CTF"
+	ParseErrorChat       ParseErrorCategory = "chat"
+	ParseErrorTeamScores ParseErrorCategory = "team score"
+)
+
+// ParseError is a log line that could not be parsed
+type ParseError struct {
+	Category   ParseErrorCategory
+	LineNumber int // Line in the log file it was read from, 0 until the error is recorded
+	Line       string
+	Err        error `json:"-"`
+	Reason     string
+}
+
+// NewParseError creates the error for a line of the category
+func NewParseError(category ParseErrorCategory, line string, err error) *ParseError {
+	return &ParseError{Category: category, Line: line, Err: err, Reason: err.Error()}
+}
+
+func (e *ParseError) Error() string {
+	if e.LineNumber == 0 {
+		return e.Reason
+	}
+	return fmt.Sprintf("line %d: %s", e.LineNumber, e.Reason)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// RecordParseError counts a line that could not be parsed, and keeps it for display
+func (g *Game) RecordParseError(err *ParseError) *Game {
+	g.ParseErrors++
+	if g.ParseErrorCounts == nil {
+		g.ParseErrorCounts = make(map[ParseErrorCategory]int)
+	}
+	g.ParseErrorCounts[err.Category]++
+
+	g.ParseErrorLog = append(g.ParseErrorLog, *err)
+	if len(g.ParseErrorLog) > parseErrorLogSize {
+		g.ParseErrorLog = g.ParseErrorLog[len(g.ParseErrorLog)-parseErrorLogSize:]
+	}
+	return g
+}
+
+// ParseErrorSummary describes the lines that could not be parsed, e.g.
+// "3 kill lines and 1 chat line could not be parsed", or "" if all were parsed
+func ParseErrorSummary(counts map[ParseErrorCategory]int) string {
+	categories := make([]ParseErrorCategory, 0, len(counts))
+	for category, count := range counts {
+		if count > 0 {
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) == 0 {
+		return ""
+	}
+
+	// Most frequent first
+	sort.Slice(categories, func(i, j int) bool {
+		if counts[categories[i]] != counts[categories[j]] {
+			return counts[categories[i]] > counts[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	parts := make([]string, 0, len(categories))
+	for _, category := range categories {
+		noun := "lines"
+		if counts[category] == 1 {
+			noun = "line"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s %s", counts[category], category, noun))
+	}
+
+	list := parts[0]
+	if len(parts) > 1 {
+		list = strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+	}
+	return list + " could not be parsed"
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestParseErrorSummary(t *testing.T) {
+	tests := []struct {
+		counts   map[ParseErrorCategory]int
+		expected string
+	}{
+		{nil, ""},
+		{map[ParseErrorCategory]int{ParseErrorKill: 0}, ""},
+		{map[ParseErrorCategory]int{ParseErrorKill: 3}, "3 kill lines could not be parsed"},
+		{map[ParseErrorCategory]int{ParseErrorChat: 1, ParseErrorKill: 3}, "3 kill lines and 1 chat line could not be parsed"},
+		{
+			map[ParseErrorCategory]int{ParseErrorChat: 1, ParseErrorItem: 1, ParseErrorKill: 2},
+			"2 kill lines, 1 chat line and 1 item line could not be parsed",
+		},
+	}
+	for _, tt := range tests {
+		if summary := ParseErrorSummary(tt.counts); summary != tt.expected {
+			t.Errorf("ParseErrorSummary(%v) = %q, expected %q", tt.counts, summary, tt.expected)
+		}
+	}
+}
+
+func TestRecordParseError_CountsAndKeepsMostRecent(t *testing.T) {
+	game := NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for i := 1; i <= parseErrorLogSize+5; i++ {
+		parseErr := NewParseError(ParseErrorKill, fmt.Sprintf("line %d", i), errors.New("invalid kill event"))
+		parseErr.LineNumber = i
+		game.RecordParseError(parseErr)
+	}
+	game.RecordParseError(NewParseError(ParseErrorChat, "say:", errors.New("invalid chat message")))
+
+	if game.ParseErrors != parseErrorLogSize+6 {
+		t.Errorf("Expected %d parse errors, got %d", parseErrorLogSize+6, game.ParseErrors)
+	}
+	if game.ParseErrorCounts[ParseErrorKill] != parseErrorLogSize+5 || game.ParseErrorCounts[ParseErrorChat] != 1 {
+		t.Errorf("Unexpected counts per category: %v", game.ParseErrorCounts)
+	}
+	if len(game.ParseErrorLog) != parseErrorLogSize {
+		t.Fatalf("Expected %d lines kept, got %d", parseErrorLogSize, len(game.ParseErrorLog))
+	}
+	if first := game.ParseErrorLog[0]; first.LineNumber != 7 {
+		t.Errorf("Expected the oldest kept line to be line 7, got %d", first.LineNumber)
+	}
+	if last := game.ParseErrorLog[len(game.ParseErrorLog)-1]; last.Category != ParseErrorChat {
+		t.Errorf("Expected the most recent line to be the chat line, got %s", last.Category)
+	}
+}
+
+func TestParseError_Error(t *testing.T) {
+	cause := errors.New("invalid kill event")
+	parseErr := NewParseError(ParseErrorKill, "Kill:", cause)
+	if parseErr.Error() != "invalid kill event" {
+		t.Errorf("Expected the reason before the line number is known, got %q", parseErr.Error())
+	}
+	parseErr.LineNumber = 12
+	if parseErr.Error() != "line 12: invalid kill event" {
+		t.Errorf("Expected the line number in the error, got %q", parseErr.Error())
+	}
+	if !errors.Is(parseErr, cause) {
+		t.Error("Expected the parse error to wrap its cause")
+	}
+}
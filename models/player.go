@@ -4,11 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"time"
 )
 
+// WinningScore is the score a player must pass to win the game
+const WinningScore = 16
+
 type Player struct {
 	// Player identity
 	Name string
+	Team Team
 
 	// Ranking
 	Rank     int
@@ -29,6 +34,10 @@ type Player struct {
 	RoundKills  int
 	RoundDeaths int
 
+	// Kills against bots, weighted with the "reduce" bot policy
+	BotKills      int
+	RoundBotKills int
+
 	// Weapon-specific kills
 	RocketKills      int
 	RoundRocketKills int
@@ -48,9 +57,36 @@ type Player struct {
 	RoundKillingStreak        int
 	RoundCurrentKillingStreak int
 
+	// CTF flags
+	Captures      int
+	RoundCaptures int
+
+	FlagReturns      int
+	RoundFlagReturns int
+
+	FlagTakes      int
+	RoundFlagTakes int
+
+	// Item pickups by class name, e.g. item_quad
+	Items      map[string]int
+	RoundItems map[string]int
+
+	// Time in the game during live rounds
+	TimePlayed      time.Duration
+	RoundTimePlayed time.Duration
+	RoundJoinedLate bool
+
+	// Score already drunk, as confirmed by a judge
+	Drank float64
+
+	// Skill rating
+	Rating     float64
+	RatingDiff float64
+
 	// Flags
 	IsDrinkingCider bool
 	IsIgnored       bool
+	IsBot           bool
 }
 
 func formatCiders(score float64) string {
@@ -155,6 +191,14 @@ func (p *Player) SubtractKills() *Player {
 	return p
 }
 
+func (p *Player) IncrementBotKills() *Player {
+	if p.IsIgnored {
+		return p
+	}
+	p.RoundBotKills++
+	return p
+}
+
 // Weapon-specific kills
 
 func (p *Player) IncrementRocketKills() *Player {
@@ -181,6 +225,93 @@ func (p *Player) IncrementGauntletKills() *Player {
 	return p
 }
 
+// Flag tracking
+
+func (p *Player) IncrementCaptures() *Player {
+	if p.IsIgnored {
+		return p
+	}
+	p.RoundCaptures++
+	return p
+}
+
+func (p *Player) IncrementFlagReturns() *Player {
+	if p.IsIgnored {
+		return p
+	}
+	p.RoundFlagReturns++
+	return p
+}
+
+func (p *Player) IncrementFlagTakes() *Player {
+	if p.IsIgnored {
+		return p
+	}
+	p.RoundFlagTakes++
+	return p
+}
+
+// Item tracking
+
+func (p *Player) IncrementItem(item string) *Player {
+	if p.IsIgnored {
+		return p
+	}
+	if p.RoundItems == nil {
+		p.RoundItems = make(map[string]int)
+	}
+	p.RoundItems[item]++
+	return p
+}
+
+// RoundMajorItems returns the number of major items picked up in the current round
+func (p *Player) RoundMajorItems() int {
+	count := 0
+	for item, n := range p.RoundItems {
+		if IsMajorItem(item) {
+			count += n
+		}
+	}
+	return count
+}
+
+// WeaponPickups returns the number of weapons picked up in the saved rounds
+func (p *Player) WeaponPickups() int {
+	count := 0
+	for item, n := range p.Items {
+		if IsWeapon(item) {
+			count += n
+		}
+	}
+	return count
+}
+
+// Time tracking
+
+func (p *Player) AddRoundTimePlayed(d time.Duration) *Player {
+	if p.IsIgnored {
+		return p
+	}
+	p.RoundTimePlayed += d
+	return p
+}
+
+func (p *Player) SetRoundJoinedLate(b bool) *Player {
+	if p.IsIgnored {
+		return p
+	}
+	p.RoundJoinedLate = b
+	return p
+}
+
+// KillsPerMinute returns the kills per minute played in the saved rounds
+func (p *Player) KillsPerMinute() float64 {
+	if p.TimePlayed < time.Minute {
+		return 0
+	}
+	return float64(p.Kills) / p.TimePlayed.Minutes()
+}
+
 // Death tracking
 
 func (p *Player) IncrementDeaths() *Player {
@@ -207,14 +338,15 @@ func (p *Player) IncrementSuicideDeaths() *Player {
 
 // Round management
 
-func (p *Player) SaveRound(fragLimit int) *Player {
+// SaveRoundDiff saves the round with the given score difference, e.g. the
+// team's share in team games
+func (p *Player) SaveRoundDiff(diff float64) *Player {
 	if p.IsIgnored {
 		return p
 	}
 
 	// Calculate score difference
 	oldScore := p.Score
-	diff := float64(p.RoundKills) / float64(fragLimit)
 	p.Score += diff
 
 	// Update formatted scores
@@ -224,20 +356,40 @@ func (p *Player) SaveRound(fragLimit int) *Player {
 
 	// Commit round stats to overall stats
 	p.Kills += p.RoundKills
+	p.BotKills += p.RoundBotKills
 	p.Deaths += p.RoundDeaths
 	p.RocketKills += p.RoundRocketKills
 	p.RailgunKills += p.RoundRailgunKills
 	p.GauntletKills += p.RoundGauntletKills
 	p.SuicideDeaths += p.RoundSuicideDeaths
+	p.Captures += p.RoundCaptures
+	p.FlagReturns += p.RoundFlagReturns
+	p.FlagTakes += p.RoundFlagTakes
+	p.TimePlayed += p.RoundTimePlayed
+	for item, n := range p.RoundItems {
+		if p.Items == nil {
+			p.Items = make(map[string]int)
+		}
+		p.Items[item] += n
+	}
 	p.KillingStreak = max(p.KillingStreak, p.RoundKillingStreak)
 
 	// Reset round stats
 	p.RoundKills = 0
+	p.RoundBotKills = 0
 	p.RoundDeaths = 0
 	p.RoundRocketKills = 0
 	p.RoundRailgunKills = 0
 	p.RoundGauntletKills = 0
 	p.RoundSuicideDeaths = 0
+	p.RoundCaptures = 0
+	p.RoundFlagReturns = 0
+	p.RoundFlagTakes = 0
+	p.RoundItems = nil
+	p.RoundTimePlayed = 0
+	p.RoundJoinedLate = false
+	p.RoundKillingStreak = 0
+	p.RoundCurrentKillingStreak = 0
 
 	p.RecalculateKillDeathRatio()
 
@@ -250,18 +402,39 @@ func (p *Player) DiscardRound() *Player {
 	}
 
 	p.RoundKills = 0
+	p.RoundBotKills = 0
 	p.RoundDeaths = 0
 	p.RoundRocketKills = 0
 	p.RoundRailgunKills = 0
 	p.RoundGauntletKills = 0
 	p.RoundSuicideDeaths = 0
+	p.RoundCaptures = 0
+	p.RoundFlagReturns = 0
+	p.RoundFlagTakes = 0
+	p.RoundItems = nil
+	p.RoundTimePlayed = 0
+	p.RoundJoinedLate = false
 	p.RoundKillingStreak = 0
+	p.RoundCurrentKillingStreak = 0
 
 	p.RecalculateKillDeathRatio()
 
 	return p
 }
 
+// Rating
+
+func (p *Player) UpdateRating(diff float64) *Player {
+	if p.IsIgnored {
+		return p
+	}
+
+	p.Rating += diff
+	p.RatingDiff = diff
+
+	return p
+}
+
 // Player state setters
 
 func (p *Player) SetDrinkingCider(b bool) *Player {
@@ -269,6 +442,23 @@ func (p *Player) SetDrinkingCider(b bool) *Player {
 	return p
 }
 
+// RecalculateScore14 formats the scores again, e.g. after switching to cider
+func (p *Player) RecalculateScore14() *Player {
+	p.Score14 = calculateScore14(p.Score, p.IsDrinkingCider)
+	p.Diff14 = calculateScore14(p.Diff, p.IsDrinkingCider)
+	return p
+}
+
+func (p *Player) SetDrank(score float64) *Player {
+	p.Drank = score
+	return p
+}
+
+// Owed14 returns what the player still has to drink
+func (p *Player) Owed14() string {
+	return calculateScore14(p.Score-p.Drank, p.IsDrinkingCider)
+}
+
 func (p *Player) SetIsIgnored(b bool) *Player {
 	p.IsIgnored = b
 	return p
@@ -285,6 +475,18 @@ func (p *Player) SetRank(rank int) *Player {
 	return p
 }
 
+// Winners
+
+// IsRoundWinner returns true if the player won the last saved round
+func (p *Player) IsRoundWinner() bool {
+	return math.Abs(p.Diff-1) <= 1e-5
+}
+
+// IsGameWinner returns true if the player is in first place and has passed the winning score
+func (p *Player) IsGameWinner() bool {
+	return p.Score > WinningScore && p.Rank == 1
+}
+
 // ToJson returns the JSON representation of the player state
 func (p *Player) ToJson() string {
 	playerJSON, err := json.Marshal(p)
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+)
+
+const (
+	// InitialRating is the skill rating given to players without history
+	InitialRating = 1500.0
+
+	// ratingK is the maximum rating change a player can get from one round
+	ratingK = 32.0
+)
+
+// Rating is the persisted skill rating of a single player
+type Rating struct {
+	Value  float64 `json:"rating"`
+	Rounds int     `json:"rounds"`
+}
+
+// Ratings holds skill ratings across events, keyed by player name
+type Ratings struct {
+	Players map[string]*Rating `json:"players"`
+
+	// AppliedRounds are the keys of the rounds included in the ratings, so
+	// reading a log again does not apply its rounds twice, see Round.Key
+	AppliedRounds []string `json:"applied_rounds"`
+}
+
+// NewRatings creates an empty set of ratings
+func NewRatings() *Ratings {
+	return &Ratings{
+		Players: make(map[string]*Rating),
+	}
+}
+
+// LoadRatings loads ratings from a JSON file
+// A missing file is not an error and results in empty ratings
+func LoadRatings(filepath string) (*Ratings, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewRatings(), nil
+		}
+		return nil, fmt.Errorf("failed to read ratings file: %w", err)
+	}
+
+	ratings := NewRatings()
+	if err := json.Unmarshal(data, ratings); err != nil {
+		return nil, fmt.Errorf("failed to parse ratings JSON: %w", err)
+	}
+	if ratings.Players == nil {
+		ratings.Players = make(map[string]*Rating)
+	}
+
+	return ratings, nil
+}
+
+// SaveToFile writes the ratings to a temporary file and renames it into
+// place, so a crash never leaves a half written ratings file behind
+func (r *Ratings) SaveToFile(filepath string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode ratings: %w", err)
+	}
+
+	tmp := filepath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write ratings file: %w", err)
+	}
+	if err := os.Rename(tmp, filepath); err != nil {
+		return fmt.Errorf("failed to write ratings file: %w", err)
+	}
+	return nil
+}
+
+// Get returns the rating for a player, or the initial rating if unknown
+func (r *Ratings) Get(playerName string) float64 {
+	if rating, ok := r.Players[playerName]; ok {
+		return rating.Value
+	}
+	return InitialRating
+}
+
+// Set stores the rating for a player and counts the round
+func (r *Ratings) Set(playerName string, value float64) {
+	rating, ok := r.Players[playerName]
+	if !ok {
+		rating = &Rating{}
+		r.Players[playerName] = rating
+	}
+	rating.Value = value
+	rating.Rounds++
+}
+
+// IsApplied returns true if the round with the key is already included in the ratings
+func (r *Ratings) IsApplied(roundKey string) bool {
+	return slices.Contains(r.AppliedRounds, roundKey)
+}
+
+// MarkApplied records that the round with the key is included in the ratings
+func (r *Ratings) MarkApplied(roundKey string) {
+	r.AppliedRounds = append(r.AppliedRounds, roundKey)
+}
+
+// Names returns the rated player names sorted by rating (highest first)
+func (r *Ratings) Names() []string {
+	names := make([]string, 0, len(r.Players))
+	for name := range r.Players {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		rating1 := r.Players[names[i]]
+		rating2 := r.Players[names[j]]
+		if rating1.Value != rating2.Value {
+			return rating1.Value > rating2.Value
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// expectedOutcome returns the expected score of a player with rating a against rating b
+func expectedOutcome(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// calculateRatingDiffs treats a round as a free-for-all match and returns the
+// rating change per player. Every pair of players is scored as a duel won by
+// the player with the most round kills, and the sum is scaled so a player can
+// at most gain or lose ratingK in a round regardless of the number of players.
+func calculateRatingDiffs(players []*Player) map[string]float64 {
+	diffs := make(map[string]float64, len(players))
+	if len(players) < 2 {
+		return diffs
+	}
+
+	scale := ratingK / float64(len(players)-1)
+	for _, p1 := range players {
+		var sum float64
+		for _, p2 := range players {
+			if p1 == p2 {
+				continue
+			}
+
+			actual := 0.5
+			if p1.RoundKills > p2.RoundKills {
+				actual = 1
+			} else if p1.RoundKills < p2.RoundKills {
+				actual = 0
+			}
+
+			sum += actual - expectedOutcome(p1.Rating, p2.Rating)
+		}
+		diffs[p1.Name] = scale * sum
+	}
+
+	return diffs
+}
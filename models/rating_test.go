@@ -0,0 +1,184 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestCalculateRatingDiffs(t *testing.T) {
+	tests := []struct {
+		name     string
+		players  []*Player
+		expected map[string]float64
+	}{
+		{
+			name: "Single player gets no rating change",
+			players: []*Player{
+				{Name: "A", Rating: 1500, RoundKills: 10},
+			},
+			expected: map[string]float64{},
+		},
+		{
+			name: "Equal ratings, winner gains what loser loses",
+			players: []*Player{
+				{Name: "A", Rating: 1500, RoundKills: 10},
+				{Name: "B", Rating: 1500, RoundKills: 5},
+			},
+			expected: map[string]float64{"A": 16, "B": -16},
+		},
+		{
+			name: "Equal kills and ratings is a draw",
+			players: []*Player{
+				{Name: "A", Rating: 1500, RoundKills: 7},
+				{Name: "B", Rating: 1500, RoundKills: 7},
+			},
+			expected: map[string]float64{"A": 0, "B": 0},
+		},
+		{
+			name: "Three players are scaled by number of opponents",
+			players: []*Player{
+				{Name: "A", Rating: 1500, RoundKills: 20},
+				{Name: "B", Rating: 1500, RoundKills: 10},
+				{Name: "C", Rating: 1500, RoundKills: 0},
+			},
+			expected: map[string]float64{"A": 16, "B": 0, "C": -16},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := calculateRatingDiffs(tt.players)
+			if len(diffs) != len(tt.expected) {
+				t.Fatalf("Expected %d diffs, got %d", len(tt.expected), len(diffs))
+			}
+			for name, expected := range tt.expected {
+				if math.Abs(diffs[name]-expected) > 1e-9 {
+					t.Errorf("%s: expected diff %.2f, got %.2f", name, expected, diffs[name])
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateRatingDiffs_UpsetGainsMore(t *testing.T) {
+	favourite := &Player{Name: "Favourite", Rating: 1700, RoundKills: 5}
+	underdog := &Player{Name: "Underdog", Rating: 1300, RoundKills: 10}
+
+	diffs := calculateRatingDiffs([]*Player{favourite, underdog})
+
+	if diffs["Underdog"] <= 16 {
+		t.Errorf("Expected underdog to gain more than 16 for an upset, got %.2f", diffs["Underdog"])
+	}
+	if math.Abs(diffs["Underdog"]+diffs["Favourite"]) > 1e-9 {
+		t.Errorf("Expected rating changes to sum to zero, got %.2f and %.2f", diffs["Underdog"], diffs["Favourite"])
+	}
+}
+
+func TestLoadRatings_NonExistent(t *testing.T) {
+	ratings, err := LoadRatings(filepath.Join(t.TempDir(), "ratings.json"))
+	if err != nil {
+		t.Fatalf("Expected no error for missing ratings file, got %v", err)
+	}
+	if len(ratings.Players) != 0 {
+		t.Errorf("Expected empty ratings, got %d players", len(ratings.Players))
+	}
+	if ratings.Get("Unknown") != InitialRating {
+		t.Errorf("Expected initial rating %.0f for unknown player, got %.0f", InitialRating, ratings.Get("Unknown"))
+	}
+}
+
+func TestLoadRatings_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	if err := os.WriteFile(path, []byte("invalid json{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadRatings(path); err == nil {
+		t.Error("Expected error for invalid JSON, got nil")
+	}
+}
+
+func TestSave_PersistsRatingsAcrossEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	cfg := &config.Config{RatingsFile: path}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// First event: A beats B in a single round
+	game := NewGame(cfg, logger)
+	game.NewMap("q3dm1", "2024-04-19 16:00:00")
+	game.NewMap("q3dm17", "2024-04-19 16:02:00")
+	game.RecordKill("A", "B", "MOD_RAILGUN")
+	game.Save()
+
+	if len(game.Rounds) != 1 {
+		t.Fatalf("Expected 1 round in history, got %d", len(game.Rounds))
+	}
+	round := game.Rounds[0]
+	if round.MapName != "q3dm17" || round.StartedAt != "2024-04-19 16:02:00" {
+		t.Errorf("Unexpected round info: %+v", round)
+	}
+	result := round.GetResult("A")
+	if result == nil || result.Kills != 1 || result.RatingDiff != 16 || result.Rating != 1516 {
+		t.Errorf("Unexpected round result for A: %+v", result)
+	}
+
+	// Second event: ratings are seeded from the file
+	ratings, err := LoadRatings(path)
+	if err != nil {
+		t.Fatalf("LoadRatings failed: %v", err)
+	}
+	nextGame := NewGame(cfg, logger)
+	nextGame.Ratings = ratings
+
+	if rating := nextGame.GetOrCreatePlayer("A").Rating; rating != 1516 {
+		t.Errorf("Expected A to be seeded with 1516, got %.0f", rating)
+	}
+	if rating := nextGame.GetOrCreatePlayer("B").Rating; rating != 1484 {
+		t.Errorf("Expected B to be seeded with 1484, got %.0f", rating)
+	}
+	if rating := nextGame.GetOrCreatePlayer("Newcomer").Rating; rating != InitialRating {
+		t.Errorf("Expected newcomer to be seeded with %.0f, got %.0f", InitialRating, rating)
+	}
+	if rounds := ratings.Players["A"].Rounds; rounds != 1 {
+		t.Errorf("Expected A to have 1 rated round, got %d", rounds)
+	}
+}
+
+func TestSave_AppliesRoundsOfAnotherLogWithTheSameIds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	cfg := &config.Config{RatingsFile: path}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Native logs without g_timestamp start every event at the same time
+	playEvent := func(winner, loser string) *Game {
+		ratings, err := LoadRatings(path)
+		if err != nil {
+			t.Fatalf("LoadRatings failed: %v", err)
+		}
+		game := NewGame(cfg, logger)
+		game.Ratings = ratings
+		game.NewMap("q3dm1", "2000-01-01 00:00:00")
+		game.NewMap("q3dm17", "2000-01-01 00:00:05")
+		game.RecordKill(winner, loser, "MOD_RAILGUN")
+		game.Save()
+		return game
+	}
+
+	playEvent("A", "B")
+	if game := playEvent("A", "B"); game.Ratings.Players["A"].Rounds != 1 {
+		t.Errorf("Expected the same round not to be applied twice, got %d rounds", game.Ratings.Players["A"].Rounds)
+	}
+	game := playEvent("C", "D")
+	if rating, ok := game.Ratings.Players["C"]; !ok || rating.Rounds != 1 {
+		t.Errorf("Expected the round of another log to be applied, got %+v", rating)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected no temporary file to be left behind, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Round is a saved round in the game history
+type Round struct {
+	Id        string
+	LegacyId  string // md5 hash of StartedAt, the id before readable ids
+	MapName   string
+	StartedAt string
+	EndedAt   string
+	GameType  int
+	Results   []RoundResult
+
+	// Why the round was ignored, empty for rounds that count
+	IgnoredReason string
+
+	// Team scores, only set in team games
+	RedScore  int
+	BlueScore int
+}
+
+// RoundId returns the id of the round started on the map at the timestamp,
+// e.g. "2024-04-19T16:10:12-q3dm1"
+func RoundId(timestamp, mapName string) string {
+	return strings.Replace(timestamp, " ", "T", 1) + "-" + mapName
+}
+
+// LegacyRoundId returns the md5 hash of the timestamp the round started at,
+// which was the round id before readable ids
+func LegacyRoundId(timestamp string) string {
+	hash := md5.Sum([]byte(timestamp))
+	return hex.EncodeToString(hash[:])
+}
+
+// IsIgnored returns true if the round does not count
+func (r *Round) IsIgnored() bool {
+	return r.IgnoredReason != ""
+}
+
+// Duration returns the time from the map starting to the round ending,
+// returning false if it is unknown
+func (r *Round) Duration() (time.Duration, bool) {
+	start, ok := parseTimestamp(r.StartedAt)
+	if !ok {
+		return 0, false
+	}
+	end, ok := parseTimestamp(r.EndedAt)
+	if !ok {
+		return 0, false
+	}
+	return end.Sub(start), true
+}
+
+// Key returns the round id followed by a hash of the results, e.g.
+// "2024-04-19T16:10:12-q3dm1-3f2a9c1e"
+// Round ids repeat across native logs without g_timestamp, the results don't
+func (r *Round) Key() string {
+	var sb strings.Builder
+	sb.WriteString(r.Id)
+	for _, result := range r.Results {
+		fmt.Fprintf(&sb, "|%s:%d:%d", result.Name, result.Kills, result.Deaths)
+	}
+	hash := md5.Sum([]byte(sb.String()))
+	return r.Id + "-" + hex.EncodeToString(hash[:4])
+}
+
+// HasId returns true if id is the round's id or its legacy id
+func (r *Round) HasId(id string) bool {
+	return id == r.Id || id == r.LegacyId
+}
+
+// Winner returns the winning team of a team round, or else the player with
+// the most kills, or "" if nobody played
+func (r *Round) Winner() string {
+	if IsTeamGameType(r.GameType) {
+		switch {
+		case r.RedScore > r.BlueScore:
+			return TeamRed.String()
+		case r.BlueScore > r.RedScore:
+			return TeamBlue.String()
+		default:
+			return "draw"
+		}
+	}
+	if len(r.Results) == 0 {
+		return ""
+	}
+	return r.Results[0].Name
+}
+
+// RoundResult holds the outcome of a round for a single player
+type RoundResult struct {
+	Name       string
+	Team       string
+	Kills      int
+	Deaths     int
+	Diff       float64
+	Rating     float64
+	RatingDiff float64
+
+	// Major items picked up and the share of all major items in the round
+	MajorItems int
+	Control    float64
+
+	// Time in the game during the round, and whether the player joined late
+	TimePlayed time.Duration
+	JoinedLate bool
+}
+
+// GetResult returns the result for a player, or nil if they did not play the round
+func (r *Round) GetResult(playerName string) *RoundResult {
+	for i := range r.Results {
+		if r.Results[i].Name == playerName {
+			return &r.Results[i]
+		}
+	}
+	return nil
+}
+
+// LateJoiners returns the players that joined the round late
+func (r *Round) LateJoiners() []string {
+	var names []string
+	for _, result := range r.Results {
+		if result.JoinedLate {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
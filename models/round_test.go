@@ -0,0 +1,56 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestRoundId(t *testing.T) {
+	if id := RoundId("2024-04-19 16:10:12", "q3dm1"); id != "2024-04-19T16:10:12-q3dm1" {
+		t.Errorf("Expected a readable round id, got %q", id)
+	}
+	if id := LegacyRoundId("hello"); id != "5d41402abc4b2a76b9719d911017c592" {
+		t.Errorf("Expected the md5 hash of the timestamp, got %q", id)
+	}
+}
+
+func TestIsSkipped_AcceptsReadableAndLegacyIds(t *testing.T) {
+	for _, ignored := range []string{"2024-04-19T16:02:13-q3dm17", LegacyRoundId("2024-04-19 16:02:13")} {
+		game := NewGame(&config.Config{IgnoredRounds: []string{ignored}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		game.NewMap("q3dm1", "2024-04-19 16:01:17")
+		if game.IsSkipped() {
+			t.Errorf("Expected the warmup round not to be skipped by %q", ignored)
+		}
+		game.NewMap("q3dm17", "2024-04-19 16:02:13")
+		if !game.IsSkipped() {
+			t.Errorf("Expected the round to be skipped by %q", ignored)
+		}
+	}
+}
+
+func TestRound_Winner(t *testing.T) {
+	game := NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	game.NewMap("q3dm17", "2024-04-19 16:02:13")
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.Save()
+
+	round := game.Rounds[0]
+	if round.Id != "2024-04-19T16:02:13-q3dm17" || !round.HasId(LegacyRoundId("2024-04-19 16:02:13")) {
+		t.Errorf("Unexpected round ids: %q and %q", round.Id, round.LegacyId)
+	}
+	if winner := round.Winner(); winner != "PlayerOne" {
+		t.Errorf("Expected PlayerOne to win, got %q", winner)
+	}
+
+	teamRound := &Round{GameType: GameTypeTDM, RedScore: 3, BlueScore: 5}
+	if winner := teamRound.Winner(); winner != "blue" {
+		t.Errorf("Expected blue to win, got %q", winner)
+	}
+	if winner := (&Round{}).Winner(); winner != "" {
+		t.Errorf("Expected no winner without results, got %q", winner)
+	}
+}
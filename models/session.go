@@ -0,0 +1,142 @@
+package models
+
+import (
+	"time"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+// TimestampLayout is the layout of the timestamps in the log
+const TimestampLayout = "2006-01-02 15:04:05"
+
+// session is the time a client has been in the game since it began
+type session struct {
+	playerName string
+	since      time.Time
+}
+
+// parseTimestamp parses a log timestamp, returning false if it is invalid
+func parseTimestamp(timestamp string) (time.Time, bool) {
+	t, err := time.ParseInLocation(TimestampLayout, timestamp, time.Local)
+	return t, err == nil
+}
+
+// liveThreshold is how old a log line can be and still be from a live log
+const liveThreshold = time.Minute
+
+// IsLive returns true if the log line with the timestamp was written just now
+// Timestamps further in the future than liveThreshold are not from a live log
+func IsLive(timestamp string) bool {
+	t, ok := parseTimestamp(timestamp)
+	if !ok {
+		return false
+	}
+	age := time.Since(t)
+	return age > -liveThreshold && age < liveThreshold
+}
+
+// lateJoinLimit returns how long after the map started a player may join
+func (g *Game) lateJoinLimit() time.Duration {
+	seconds := config.DefaultLateJoinSeconds
+	if g.Config != nil && g.Config.LateJoinSeconds > 0 {
+		seconds = g.Config.LateJoinSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ClientConnect ends the session of whoever was using the client slot
+// Clients also reconnect on every map change, and the loading time in
+// between is not counted as time played
+func (g *Game) ClientConnect(clientId int) *Game {
+	g.endSession(clientId)
+	return g
+}
+
+// ClientBegin starts the session of the player on a client slot once it has
+// entered the game, flagging the round when the player joined late
+func (g *Game) ClientBegin(clientId int) *Game {
+	playerName := g.ClientName(clientId)
+	now, ok := parseTimestamp(g.CurrentTime)
+	if playerName == "" || !ok {
+		return g
+	}
+
+	// Changing team begins again without reconnecting
+	if s, ok := g.sessions[clientId]; ok && s.playerName == playerName {
+		return g
+	}
+	g.endSession(clientId)
+
+	if g.sessions == nil {
+		g.sessions = make(map[int]*session)
+	}
+	g.sessions[clientId] = &session{playerName: playerName, since: now}
+	g.debug("Player entered the game", "player", playerName)
+	g.addEvent(Event{Type: EventPlayerJoined, Player: playerName})
+
+	if g.IsWarmup || g.teams[playerName] == TeamSpectator {
+		return g
+	}
+	if start, ok := parseTimestamp(g.CurrentRoundStart); ok && now.Sub(start) > g.lateJoinLimit() {
+		g.debug("Player joined after the map started", "player", playerName, "late", now.Sub(start))
+		g.GetOrCreatePlayer(playerName).SetRoundJoinedLate(true)
+	}
+	return g
+}
+
+// ClientDisconnect ends the session of a player leaving the server
+func (g *Game) ClientDisconnect(clientId int) *Game {
+	if s, ok := g.sessions[clientId]; ok {
+		g.debug("Player left the game", "player", s.playerName)
+		g.addEvent(Event{Type: EventPlayerLeft, Player: s.playerName})
+	}
+	g.endSession(clientId)
+	return g
+}
+
+// endSession counts the time played in the session and removes it
+func (g *Game) endSession(clientId int) {
+	if s, ok := g.sessions[clientId]; ok {
+		g.countTimePlayed(s)
+		delete(g.sessions, clientId)
+	}
+}
+
+// countSessions counts the time played in all sessions up to now, e.g. when
+// the round is saved
+func (g *Game) countSessions() {
+	for _, s := range g.sessions {
+		g.countTimePlayed(s)
+	}
+}
+
+// countPlayerSessions counts the time played by a player up to now, e.g.
+// before the player changes team
+func (g *Game) countPlayerSessions(playerName string) {
+	for _, s := range g.sessions {
+		if s.playerName == playerName {
+			g.countTimePlayed(s)
+		}
+	}
+}
+
+// countTimePlayed adds the time since the session was last counted to the
+// round, counting only the time after the map started and not spectating
+func (g *Game) countTimePlayed(s *session) {
+	now, ok := parseTimestamp(g.CurrentTime)
+	if !ok {
+		return
+	}
+	since := s.since
+	s.since = now
+	if g.IsWarmup || g.teams[s.playerName] == TeamSpectator {
+		return
+	}
+
+	if start, ok := parseTimestamp(g.CurrentRoundStart); ok && start.After(since) {
+		since = start
+	}
+	if now.After(since) {
+		g.GetOrCreatePlayer(s.playerName).AddRoundTimePlayed(now.Sub(since))
+	}
+}
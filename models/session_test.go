@@ -0,0 +1,116 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestSessions_TimePlayedAndLateJoin(t *testing.T) {
+	cfg := &config.Config{LateJoinSeconds: 60}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	at := func(timestamp string) *Game {
+		game.CurrentTime = "2025-12-05 " + timestamp
+		return game
+	}
+
+	at("14:00:00").NewMap("q3dm1", game.CurrentTime)
+	at("14:00:30").NewMap("q3dm6", game.CurrentTime)
+	game.SetClientName(0, "OnTime")
+	game.SetClientName(1, "Late")
+	game.SetClientName(2, "Leaver")
+	game.SetClientName(3, "Watcher")
+	game.SetPlayerTeam("Watcher", TeamSpectator)
+
+	at("14:00:30").ClientBegin(0)
+	at("14:00:30").ClientBegin(2)
+	at("14:00:30").ClientBegin(3)
+	at("14:03:30").ClientBegin(1)
+	at("14:04:00").RecordKill("Leaver", "Late", "MOD_RAILGUN")
+	at("14:04:30").ClientDisconnect(2)
+	at("14:05:00").ClientBegin(0) // Team change begins again
+	at("14:05:00").RecordKill("OnTime", "Late", "MOD_RAILGUN")
+	at("14:10:30").Save()
+
+	expected := map[string]time.Duration{
+		"OnTime": 10 * time.Minute,
+		"Late":   7 * time.Minute,
+		"Leaver": 4 * time.Minute,
+	}
+	for name, played := range expected {
+		if game.Players[name].TimePlayed != played {
+			t.Errorf("%s: expected %s played, got %s", name, played, game.Players[name].TimePlayed)
+		}
+	}
+	if _, ok := game.Players["Watcher"]; ok {
+		t.Error("Expected no time to be counted for spectators")
+	}
+
+	round := game.Rounds[0]
+	if late := round.LateJoiners(); len(late) != 1 || late[0] != "Late" {
+		t.Errorf("Expected Late to have joined late, got %v", late)
+	}
+	if result := round.GetResult("Late"); result == nil || result.TimePlayed != 7*time.Minute {
+		t.Errorf("Expected 7 minutes played in the round, got %+v", result)
+	}
+	if game.Players["Late"].RoundJoinedLate {
+		t.Error("Expected the late flag to be reset after saving")
+	}
+
+	// 1 kill in 10 minutes
+	if kpm := game.Players["OnTime"].KillsPerMinute(); kpm != 0.1 {
+		t.Errorf("Expected 0.1 kills per minute, got %v", kpm)
+	}
+
+	// Time after the round is saved is not counted
+	at("14:12:00").ClientDisconnect(0)
+	if game.Players["OnTime"].RoundTimePlayed != 0 {
+		t.Errorf("Expected no time counted during warmup, got %s", game.Players["OnTime"].RoundTimePlayed)
+	}
+}
+
+func TestSessions_ReconnectOnMapChangeSkipsLoading(t *testing.T) {
+	game := NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	at := func(timestamp string) *Game {
+		game.CurrentTime = "2025-12-05 " + timestamp
+		return game
+	}
+
+	at("14:00:00").NewMap("q3dm1", game.CurrentTime)
+	game.SetClientName(0, "PlayerOne")
+	at("14:00:05").ClientBegin(0)
+
+	// The next map starts, and the client reconnects and loads the map for 10 seconds
+	at("14:01:00").NewMap("q3dm6", game.CurrentTime)
+	at("14:01:00").ClientConnect(0)
+	at("14:01:10").ClientBegin(0)
+	at("14:02:10").RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	at("14:02:10").Save()
+
+	if played := game.Players["PlayerOne"].TimePlayed; played != time.Minute {
+		t.Errorf("Expected 1 minute played, got %s", played)
+	}
+	if game.Rounds[0].GetResult("PlayerOne").JoinedLate {
+		t.Error("Expected loading the map not to count as a late join")
+	}
+}
+
+func TestIsLive(t *testing.T) {
+	tests := []struct {
+		timestamp string
+		expected  bool
+	}{
+		{time.Now().Format(TimestampLayout), true},
+		{time.Now().Add(-time.Hour).Format(TimestampLayout), false},
+		{time.Now().Add(time.Hour).Format(TimestampLayout), false},
+		{"not a time", false},
+	}
+	for _, tt := range tests {
+		if result := IsLive(tt.timestamp); result != tt.expected {
+			t.Errorf("IsLive(%q) = %v, want %v", tt.timestamp, result, tt.expected)
+		}
+	}
+}
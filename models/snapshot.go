@@ -0,0 +1,129 @@
+package models
+
+import (
+	"maps"
+	"slices"
+)
+
+// Snapshot is a copy of the game state taken after a log line was parsed
+// It is never modified, so consumers can read it from any goroutine while
+// the parser goes on mutating the game
+type Snapshot struct {
+	// Players that are not hidden, sorted for display
+	Players []*Player
+
+	// Game state
+	CurrentRoundId    string
+	CurrentMapName    string
+	CurrentRoundStart string
+	CurrentTime       string
+	IsWarmup          bool
+	GameType          int
+
+	// Team scores of the current round, nil outside team games
+	TeamScores map[Team]int
+
+	// Awards for the saved rounds
+	Awards []Award
+
+	// Most recent kills and chat messages, oldest first
+	KillFeed []Kill
+	ChatLog  []ChatMessage
+
+	// Rounds and events are append-only, so they share the game's storage
+	Rounds []*Round
+	Events []Event
+
+	// Parser health
+	LinesProcessed   int
+	ParseErrors      int
+	ParseErrorCounts map[ParseErrorCategory]int
+	ParseErrorLog    []ParseError
+	BytesRead        int64
+
+	// Maximum statistics
+	MaxKills          int
+	MaxDeaths         int
+	MaxKillDeathRatio float64
+	MaxKillingStreak  int
+	MaxRocketKills    int
+	MaxRailgunKills   int
+	MaxGauntletKills  int
+	MaxSuicides       int
+}
+
+// Snapshot copies the game state
+// Must be called from the goroutine that mutates the game
+func (g *Game) Snapshot() *Snapshot {
+	sorted := g.GetSortedPlayers()
+	players := make([]*Player, 0, len(sorted))
+	for _, p := range sorted {
+		players = append(players, p.clone())
+	}
+
+	s := &Snapshot{
+		Players:           players,
+		CurrentRoundId:    g.CurrentRoundId,
+		CurrentMapName:    g.CurrentMapName,
+		CurrentRoundStart: g.CurrentRoundStart,
+		CurrentTime:       g.CurrentTime,
+		IsWarmup:          g.IsWarmup,
+		GameType:          g.GameType,
+		Awards:            g.Awards(),
+		KillFeed:          slices.Clone(g.KillFeed),
+		ChatLog:           slices.Clone(g.ChatLog),
+		Rounds:            g.Rounds[:len(g.Rounds):len(g.Rounds)],
+		Events:            g.Events[:len(g.Events):len(g.Events)],
+		LinesProcessed:    g.LinesProcessed,
+		ParseErrors:       g.ParseErrors,
+		ParseErrorCounts:  maps.Clone(g.ParseErrorCounts),
+		ParseErrorLog:     slices.Clone(g.ParseErrorLog),
+		BytesRead:         g.BytesRead,
+		MaxKills:          g.MaxKills,
+		MaxDeaths:         g.MaxDeaths,
+		MaxKillDeathRatio: g.MaxKillDeathRatio,
+		MaxKillingStreak:  g.MaxKillingStreak,
+		MaxRocketKills:    g.MaxRocketKills,
+		MaxRailgunKills:   g.MaxRailgunKills,
+		MaxGauntletKills:  g.MaxGauntletKills,
+		MaxSuicides:       g.MaxSuicides,
+	}
+	if g.IsTeamGame() {
+		s.TeamScores = g.TeamRoundScores()
+	}
+	return s
+}
+
+// IsTeamGame returns true if the snapshot was taken during a team game
+func (s *Snapshot) IsTeamGame() bool {
+	return IsTeamGameType(s.GameType)
+}
+
+// RoundsPlayed returns the number of rounds that count, leaving out ignored rounds
+func (s *Snapshot) RoundsPlayed() int {
+	played := 0
+	for _, round := range s.Rounds {
+		if !round.IsIgnored() {
+			played++
+		}
+	}
+	return played
+}
+
+// Player returns the player with the name, or nil if they are not shown
+func (s *Snapshot) Player(name string) *Player {
+	for _, p := range s.Players {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of the player that shares no state with it
+func (p *Player) clone() *Player {
+	c := *p
+	c.Items = maps.Clone(p.Items)
+	c.RoundItems = maps.Clone(p.RoundItems)
+	return &c
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func TestSnapshot_IsNotChangedByTheGame(t *testing.T) {
+	game := NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2025-12-05 14:00:00")
+	game.NewMap("q3dm6", "2025-12-05 14:00:30")
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.RecordItem("PlayerOne", ItemQuad)
+
+	snapshot := game.Snapshot()
+
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.RecordItem("PlayerOne", ItemQuad)
+	game.Save()
+
+	player := snapshot.Player("PlayerOne")
+	if player == nil || player.RoundKills != 1 || player.RoundItems[ItemQuad] != 1 {
+		t.Errorf("Expected the snapshot to keep 1 kill and 1 quad, got %+v", player)
+	}
+	if len(snapshot.Events) != 3 || len(snapshot.Rounds) != 0 || snapshot.IsWarmup {
+		t.Errorf("Expected the snapshot to keep the events and rounds, got %d events and %d rounds",
+			len(snapshot.Events), len(snapshot.Rounds))
+	}
+	if len(snapshot.KillFeed) != 1 {
+		t.Errorf("Expected 1 kill in the kill feed, got %d", len(snapshot.KillFeed))
+	}
+}
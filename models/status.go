@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ServerStatus is the state of the Quake 3 server as reported by getstatus
+type ServerStatus struct {
+	Hostname  string
+	MapName   string
+	Info      map[string]string
+	Players   []ConnectedPlayer
+	UpdatedAt time.Time
+}
+
+// ConnectedPlayer is a player connected to the server
+type ConnectedPlayer struct {
+	Name  string
+	Score int
+	Ping  int
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SaveToFile writes the snapshot as JSON, e.g. to keep the final state of an event
+func (s *Snapshot) SaveToFile(filepath string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode game state: %w", err)
+	}
+	if err := os.WriteFile(filepath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// Summary describes the end of the event: the winner, the standings and the awards
+func (s *Snapshot) Summary() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "💀 Deathquake finished, rounds played: %d\n", s.RoundsPlayed())
+	if len(s.Players) > 0 && s.Players[0].Rank == 1 {
+		leader := s.Players[0]
+		if leader.IsGameWinner() {
+			fmt.Fprintf(&sb, "🏆 %s won with %s\n", leader.Name, leader.Score14)
+		} else {
+			fmt.Fprintf(&sb, "%s leads with %s\n", leader.Name, leader.Score14)
+		}
+	}
+
+	sb.WriteString("\n")
+	for _, p := range s.Players {
+		if p.Rank == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "%3d. %-20s %-22s %4d kills %4d deaths\n", p.Rank, p.Name, p.Score14, p.Kills, p.Deaths)
+	}
+
+	if len(s.Awards) > 0 {
+		sb.WriteString("\n")
+		for _, award := range s.Awards {
+			fmt.Fprintf(&sb, "%s\n", award)
+		}
+	}
+
+	return sb.String()
+}
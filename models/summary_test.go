@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func newSummaryGame() *Game {
+	game := NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", "2025-12-05 14:00:00")
+	game.NewMap("q3dm6", "2025-12-05 14:00:30")
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.RecordKill("PlayerTwo", "PlayerOne", "MOD_RAILGUN")
+	game.RecordItem("PlayerOne", ItemQuad)
+	game.Save()
+	return game
+}
+
+func TestSnapshot_Summary(t *testing.T) {
+	summary := newSummaryGame().Snapshot().Summary()
+
+	expected := []string{
+		"Deathquake finished, rounds played: 1",
+		"PlayerOne leads with 1 beer",
+		"  1. PlayerOne            1 beer                    2 kills    1 deaths",
+		"  2. PlayerTwo            7 sips                    1 kills    2 deaths",
+		"Most Quads: PlayerOne (1)",
+	}
+	for _, line := range expected {
+		if !strings.Contains(summary, line) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", line, summary)
+		}
+	}
+}
+
+func TestSnapshot_SaveToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := newSummaryGame().Snapshot().SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state Snapshot
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("Expected the state file to be JSON: %v", err)
+	}
+	if len(state.Rounds) != 1 || len(state.Players) != 2 || state.Players[0].Name != "PlayerOne" {
+		t.Errorf("Unexpected state: %d rounds, players %+v", len(state.Rounds), state.Players)
+	}
+}
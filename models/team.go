@@ -0,0 +1,234 @@
+package models
+
+// Team is the team a player is on, as in the t\ key of ClientUserinfoChanged
+type Team int
+
+const (
+	TeamFree Team = iota
+	TeamRed
+	TeamBlue
+	TeamSpectator
+)
+
+// Game types from the g_gametype cvar
+const (
+	GameTypeFFA     = 0
+	GameTypeTourney = 1
+	GameTypeTDM     = 3
+	GameTypeCTF     = 4
+)
+
+// Flag events from CTF: lines
+const (
+	FlagTaken    = 0
+	FlagCaptured = 1
+	FlagReturned = 2
+)
+
+// String returns the lower case team name
+func (t Team) String() string {
+	switch t {
+	case TeamRed:
+		return "red"
+	case TeamBlue:
+		return "blue"
+	case TeamSpectator:
+		return "spectator"
+	default:
+		return "free"
+	}
+}
+
+// IsPlaying returns true for the red and blue teams
+func (t Team) IsPlaying() bool {
+	return t == TeamRed || t == TeamBlue
+}
+
+// IsTeamGameType returns true for the game types played in teams
+func IsTeamGameType(gameType int) bool {
+	return gameType == GameTypeTDM || gameType == GameTypeCTF
+}
+
+// IsTeamGame returns true if the current map is played in teams
+func (g *Game) IsTeamGame() bool {
+	return IsTeamGameType(g.GameType)
+}
+
+// SetGameType sets the game type of the current map
+func (g *Game) SetGameType(gameType int) *Game {
+	if gameType != g.GameType {
+		g.debug("Game type changed", "game_type", gameType)
+	}
+	g.GameType = gameType
+	g.teamScoresLogged = false
+	g.flagCarriers = nil
+	g.pendingFlag = nil
+	return g
+}
+
+// SetPlayerTeam records the team a player joined
+func (g *Game) SetPlayerTeam(playerName string, team Team) *Game {
+	if g.teams == nil {
+		g.teams = make(map[string]Team)
+	}
+	if g.teams[playerName] != team {
+		g.debug("Player joined a team", "player", playerName, "team", team)
+		// The time on the old team, or spectating, is counted before switching
+		g.countPlayerSessions(playerName)
+	}
+	g.teams[playerName] = team
+	if player, ok := g.Players[playerName]; ok {
+		player.Team = team
+	}
+	return g
+}
+
+// SetTeamScores records the final team scores of the round from the red:/blue: line
+func (g *Game) SetTeamScores(red, blue int) *Game {
+	g.debug("Team scores", "red", red, "blue", blue)
+	g.RedScore = red
+	g.BlueScore = blue
+	g.teamScoresLogged = true
+	return g
+}
+
+// RecordFlag records a flag being taken, captured or returned in CTF, as
+// logged on CTF: lines
+func (g *Game) RecordFlag(playerName string, event int) *Game {
+	// The CTF: line follows the Item: line of the same pickup
+	g.ctfLogged = true
+	g.pendingFlag = nil
+	return g.recordFlag(playerName, event)
+}
+
+// PickUpFlag records a player touching a flag, as logged on Item: lines
+// The pickup is only committed by CommitFlagPickup, because servers that log
+// CTF: lines are tracked from those instead, so events are not counted twice
+func (g *Game) PickUpFlag(playerName string, flag Team) *Game {
+	g.CommitFlagPickup()
+	if !g.ctfLogged {
+		g.pendingFlag = &flagPickup{playerName: playerName, flag: flag}
+	}
+	return g
+}
+
+// CommitFlagPickup records the last flag pickup unless a CTF: line replaced it
+func (g *Game) CommitFlagPickup() *Game {
+	pickup := g.pendingFlag
+	if pickup == nil {
+		return g
+	}
+	g.pendingFlag = nil
+	if g.flagCarriers == nil {
+		g.flagCarriers = make(map[Team]string)
+	}
+
+	team := g.teams[pickup.playerName]
+	if pickup.flag != team {
+		g.flagCarriers[pickup.flag] = pickup.playerName
+		return g.recordFlag(pickup.playerName, FlagTaken)
+	}
+
+	// Touching the own flag either captures the enemy flag being carried,
+	// or returns the own flag after it was dropped
+	for enemyFlag, carrier := range g.flagCarriers {
+		if enemyFlag != team && carrier == pickup.playerName {
+			delete(g.flagCarriers, enemyFlag)
+			return g.recordFlag(pickup.playerName, FlagCaptured)
+		}
+	}
+	return g.recordFlag(pickup.playerName, FlagReturned)
+}
+
+// flagPickup is a flag touched on an Item: line
+type flagPickup struct {
+	playerName string
+	flag       Team
+}
+
+// dropFlag clears the flag carried by a player that was killed
+func (g *Game) dropFlag(playerName string) {
+	for flag, carrier := range g.flagCarriers {
+		if carrier == playerName {
+			delete(g.flagCarriers, flag)
+		}
+	}
+}
+
+func (g *Game) recordFlag(playerName string, event int) *Game {
+	if g.IsWarmup {
+		g.debug("Ignoring flag event during warmup", "player", playerName)
+		return g
+	}
+
+	player := g.GetOrCreatePlayer(playerName)
+	switch event {
+	case FlagTaken:
+		g.debug("Flag taken", "player", playerName)
+		player.IncrementFlagTakes()
+		g.addEvent(Event{Type: EventFlagTaken, Player: playerName, Team: player.Team.String()})
+	case FlagCaptured:
+		g.debug("Flag captured", "player", playerName)
+		player.IncrementCaptures()
+		g.addEvent(Event{Type: EventFlagCaptured, Player: playerName, Team: player.Team.String()})
+	case FlagReturned:
+		g.debug("Flag returned", "player", playerName)
+		player.IncrementFlagReturns()
+		g.addEvent(Event{Type: EventFlagReturned, Player: playerName, Team: player.Team.String()})
+	}
+	return g
+}
+
+// TeamRoundScores returns the team scores of the current round, from the
+// red:/blue: line when the server logged it, otherwise from frags in TDM and
+// captures in CTF
+func (g *Game) TeamRoundScores() map[Team]int {
+	if g.teamScoresLogged {
+		return map[Team]int{TeamRed: g.RedScore, TeamBlue: g.BlueScore}
+	}
+
+	scores := map[Team]int{TeamRed: 0, TeamBlue: 0}
+	for _, p := range g.Players {
+		if p.IsIgnored || !p.Team.IsPlaying() {
+			continue
+		}
+		if g.GameType == GameTypeCTF {
+			scores[p.Team] += p.RoundCaptures
+		} else {
+			scores[p.Team] += p.RoundKills
+		}
+	}
+	return scores
+}
+
+// teamDrinkScores returns the team scores the drinking is shared by
+// When the scores are the frags of the players, kills against bots are
+// weighted like in free-for-all rounds
+func (g *Game) teamDrinkScores(scores map[Team]int) map[Team]float64 {
+	if g.teamScoresLogged || g.GameType == GameTypeCTF {
+		return map[Team]float64{TeamRed: float64(scores[TeamRed]), TeamBlue: float64(scores[TeamBlue])}
+	}
+
+	weighted := map[Team]float64{TeamRed: 0, TeamBlue: 0}
+	for _, p := range g.Players {
+		if p.IsIgnored || !p.Team.IsPlaying() {
+			continue
+		}
+		weighted[p.Team] += g.roundKillScore(p)
+	}
+	return weighted
+}
+
+// teamRoundDiffs returns the score every player on a team gets for the round
+// The drinking is shared: every player drinks the team's share, which is the
+// team score relative to the best team
+func (g *Game) teamRoundDiffs(scores map[Team]float64) map[Team]float64 {
+	best := max(scores[TeamRed], scores[TeamBlue])
+	diffs := make(map[Team]float64, len(scores))
+	for team, score := range scores {
+		if best > 0 {
+			diffs[team] = score / best
+		}
+	}
+	return diffs
+}
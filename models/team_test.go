@@ -0,0 +1,155 @@
+package models
+
+import (
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+)
+
+func newTeamGame(gameType int) *Game {
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}}
+	game := NewGame(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.SetGameType(gameType)
+	game.NewMap("q3ctf1", "2025-12-05 14:00:00")
+	game.NewMap("q3ctf2", "2025-12-05 14:01:00")
+
+	game.SetPlayerTeam("RedOne", TeamRed)
+	game.SetPlayerTeam("RedTwo", TeamRed)
+	game.SetPlayerTeam("BlueOne", TeamBlue)
+	game.SetPlayerTeam("Watcher", TeamSpectator)
+	return game
+}
+
+func TestSave_TeamDeathmatchSharesTeamScore(t *testing.T) {
+	game := newTeamGame(GameTypeTDM)
+
+	game.RecordKill("RedOne", "BlueOne", "MOD_RAILGUN")
+	game.RecordKill("RedOne", "BlueOne", "MOD_RAILGUN")
+	game.RecordKill("RedTwo", "BlueOne", "MOD_RAILGUN")
+	game.RecordKill("BlueOne", "RedTwo", "MOD_RAILGUN")
+	game.Save()
+
+	// Red got 3 frags and blue 1, so every red player drinks a full share
+	expected := map[string]float64{"RedOne": 1, "RedTwo": 1, "BlueOne": 1.0 / 3}
+	for name, diff := range expected {
+		if math.Abs(game.Players[name].Diff-diff) > 1e-5 {
+			t.Errorf("%s: expected diff %.4f, got %.4f", name, diff, game.Players[name].Diff)
+		}
+	}
+	if !game.Players["RedTwo"].IsRoundWinner() {
+		t.Error("Expected every player on the winning team to be a round winner")
+	}
+
+	round := game.Rounds[0]
+	if round.RedScore != 3 || round.BlueScore != 1 || round.GameType != GameTypeTDM {
+		t.Errorf("Expected red 3, blue 1 in a TDM round, got %+v", round)
+	}
+	if result := round.GetResult("RedOne"); result == nil || result.Team != "red" {
+		t.Errorf("Expected RedOne to be on the red team in the round, got %+v", result)
+	}
+}
+
+func TestSave_TeamDeathmatchWeightsBotKills(t *testing.T) {
+	game := newTeamGame(GameTypeTDM)
+	game.Config.BotPolicy = config.BotPolicyReduce
+	game.Config.BotKillWeight = 0.5
+	game.SetPlayerBot("BlueBot", true)
+	game.SetPlayerTeam("BlueBot", TeamBlue)
+
+	// Red only fragged the bot, blue fragged a player
+	game.RecordKill("RedOne", "BlueBot", "MOD_RAILGUN")
+	game.RecordKill("RedOne", "BlueBot", "MOD_RAILGUN")
+	game.RecordKill("BlueOne", "RedTwo", "MOD_RAILGUN")
+	game.Save()
+
+	// Red's 2 bot kills are worth 1 kill, level with blue
+	for _, name := range []string{"RedOne", "BlueOne"} {
+		if math.Abs(game.Players[name].Diff-1) > 1e-5 {
+			t.Errorf("%s: expected diff 1, got %.4f", name, game.Players[name].Diff)
+		}
+	}
+	if round := game.Rounds[0]; round.RedScore != 2 || round.BlueScore != 1 {
+		t.Errorf("Expected the round to keep the frags, got red %d and blue %d", round.RedScore, round.BlueScore)
+	}
+}
+
+func TestSave_TeamScoresFromLog(t *testing.T) {
+	game := newTeamGame(GameTypeCTF)
+
+	game.RecordKill("RedOne", "BlueOne", "MOD_RAILGUN")
+	game.RecordKill("BlueOne", "RedOne", "MOD_RAILGUN")
+	game.SetTeamScores(2, 4)
+	game.Save()
+
+	if math.Abs(game.Players["RedOne"].Diff-0.5) > 1e-5 || math.Abs(game.Players["BlueOne"].Diff-1) > 1e-5 {
+		t.Errorf("Expected diffs from the logged team scores, got red %.4f and blue %.4f",
+			game.Players["RedOne"].Diff, game.Players["BlueOne"].Diff)
+	}
+}
+
+func TestPickUpFlag(t *testing.T) {
+	game := newTeamGame(GameTypeCTF)
+
+	// RedOne takes the blue flag and brings it home
+	game.PickUpFlag("RedOne", TeamBlue)
+	game.PickUpFlag("RedOne", TeamRed)
+
+	// RedTwo takes the blue flag but is killed, BlueOne returns it
+	game.PickUpFlag("RedTwo", TeamBlue)
+	game.CommitFlagPickup()
+	game.RecordKill("BlueOne", "RedTwo", "MOD_RAILGUN")
+	game.PickUpFlag("BlueOne", TeamBlue)
+	game.CommitFlagPickup()
+
+	red := game.Players["RedOne"]
+	if red.RoundFlagTakes != 1 || red.RoundCaptures != 1 {
+		t.Errorf("Expected RedOne to take and capture, got %d takes and %d captures", red.RoundFlagTakes, red.RoundCaptures)
+	}
+	if game.Players["RedTwo"].RoundCaptures != 0 {
+		t.Error("Expected RedTwo not to capture")
+	}
+	if game.Players["BlueOne"].RoundFlagReturns != 1 {
+		t.Errorf("Expected BlueOne to return the flag, got %d returns", game.Players["BlueOne"].RoundFlagReturns)
+	}
+
+	scores := game.TeamRoundScores()
+	if scores[TeamRed] != 1 || scores[TeamBlue] != 0 {
+		t.Errorf("Expected CTF score from captures, got %v", scores)
+	}
+}
+
+func TestPickUpFlag_IgnoredWhenCTFLogged(t *testing.T) {
+	game := newTeamGame(GameTypeCTF)
+
+	// The Item: line is followed by the CTF: line of the same pickup
+	game.PickUpFlag("RedOne", TeamBlue)
+	game.RecordFlag("RedOne", FlagTaken)
+	game.CommitFlagPickup()
+
+	// Once CTF: lines are seen, Item: lines are ignored
+	game.PickUpFlag("RedOne", TeamBlue)
+	game.CommitFlagPickup()
+
+	if takes := game.Players["RedOne"].RoundFlagTakes; takes != 1 {
+		t.Errorf("Expected the flag to be taken once, got %d", takes)
+	}
+	if len(game.Events) == 0 || game.Events[len(game.Events)-1].Type != EventFlagTaken {
+		t.Error("Expected a flag taken event")
+	}
+}
+
+func TestSetPlayerTeam_AppliesToNewPlayers(t *testing.T) {
+	game := newTeamGame(GameTypeTDM)
+
+	if team := game.GetOrCreatePlayer("RedOne").Team; team != TeamRed {
+		t.Errorf("Expected new player to join the red team, got %s", team)
+	}
+
+	game.SetPlayerTeam("RedOne", TeamBlue)
+	if team := game.Players["RedOne"].Team; team != TeamBlue {
+		t.Errorf("Expected existing player to switch to the blue team, got %s", team)
+	}
+}
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// pollInterval is how often the log file is checked for new lines, and for
+// being truncated or replaced
+const pollInterval = 100 * time.Millisecond
+
+// fileLine is a line read from the log file
+type fileLine struct {
+	text string
+
+	// number is the line number in the file the line was read from
+	number int
+
+	// reopened is true for the first line read after the file was
+	// truncated or replaced
+	reopened bool
+}
+
+// follower reads the lines of a log file as they are written
+// A truncated file is read again from the start. A file replaced under the
+// same name, e.g. when rotated, is read to the end before the new file is
+// opened, so every line is known to come from the old or the new file.
+type follower struct {
+	fileName string
+	logger   *slog.Logger
+
+	file   *os.File
+	reader *bufio.Reader
+
+	// offset is the number of bytes read from the file
+	offset int64
+
+	// partial is the start of a line that is still being written
+	partial string
+
+	// lines is the number of lines read from the file
+	lines int
+
+	reopened bool
+}
+
+// followFile sends the lines of the file until ctx is cancelled, waiting
+// for the file to be created when it does not exist yet
+// lines is closed when followFile returns
+func followFile(ctx context.Context, fileName string, lines chan<- fileLine, logger *slog.Logger) error {
+	defer close(lines)
+
+	f := &follower{fileName: fileName, logger: logger}
+	if err := f.open(ctx); err != nil || f.file == nil {
+		return err
+	}
+	defer func() { f.file.Close() }()
+
+	for {
+		line, ok, err := f.readLine()
+		if err != nil {
+			return err
+		}
+		if ok {
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+		if err := f.checkFile(ctx, lines); err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// open opens the file, waiting until it exists or ctx is cancelled
+// The file is left closed when ctx is cancelled
+func (f *follower) open(ctx context.Context) error {
+	for {
+		file, err := os.Open(f.fileName)
+		if err == nil {
+			f.file = file
+			f.reader = bufio.NewReader(file)
+			f.offset = 0
+			f.partial = ""
+			f.lines = 0
+			return nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// readLine returns the next complete line, or false at the end of the file
+func (f *follower) readLine() (fileLine, bool, error) {
+	text, err := f.reader.ReadString('\n')
+	f.offset += int64(len(text))
+	if err == io.EOF {
+		f.partial += text
+		return fileLine{}, false, nil
+	}
+	if err != nil {
+		return fileLine{}, false, fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	f.lines++
+	line := fileLine{text: strings.TrimRight(f.partial+text, "\n"), number: f.lines, reopened: f.reopened}
+	f.partial = ""
+	f.reopened = false
+	return line, true, nil
+}
+
+// checkFile reopens the file when it was truncated or replaced
+// The lines still in a replaced file are sent before the new file is opened
+func (f *follower) checkFile(ctx context.Context, lines chan<- fileLine) error {
+	info, err := os.Stat(f.fileName)
+	if err != nil {
+		// Moved away and not created again yet, keep reading the old file
+		return nil
+	}
+	current, err := f.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	if os.SameFile(info, current) {
+		if info.Size() >= f.offset {
+			return nil
+		}
+		f.logger.Info("Log file was truncated, reading it from the start", "file", f.fileName)
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+		f.reader.Reset(f.file)
+		f.offset = 0
+		f.partial = ""
+		f.lines = 0
+		f.reopened = true
+		return nil
+	}
+
+	// Lines written to the old file before it was replaced
+	for {
+		line, ok, err := f.readLine()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		select {
+		case lines <- line:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	if f.partial != "" {
+		f.lines++
+		select {
+		case lines <- fileLine{text: f.partial, number: f.lines, reopened: f.reopened}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	f.logger.Info("Log file was replaced, reopening it", "file", f.fileName)
+	f.file.Close()
+	f.reopened = true
+	return f.open(ctx)
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// Format is the format of a log line
+type Format int
+
+const (
+	// FormatUnknown is a line without a recognized time prefix
+	FormatUnknown Format = iota
+
+	// FormatTimestamped is server output prefixed with the date and time,
+	// e.g. "2024-04-19 16:01:33 ]\b \bKill: ..."
+	FormatTimestamped
+
+	// FormatNative is ioquake3's own g_log games.log, prefixed with the time
+	// since the map was loaded, e.g. "  1:05 Kill: ..."
+	FormatNative
+)
+
+var nativeLinePattern = regexp.MustCompile(`^\s*(\d+):(\d\d) (.*)$`)
+
+// AddTimestamp prefixes a raw server output line with a timestamp
+func AddTimestamp(line string, t time.Time) string {
+	return t.Format(models.TimestampLayout) + " " + line
+}
+
+// HasTimestamp reports whether the line starts with a timestamp
+func HasTimestamp(line string) bool {
+	if len(line) < len(models.TimestampLayout) {
+		return false
+	}
+	_, err := time.Parse(models.TimestampLayout, line[:len(models.TimestampLayout)])
+	return err == nil
+}
+
+// DetectFormat returns the format of a single log line
+func DetectFormat(line string) Format {
+	if HasTimestamp(line) {
+		return FormatTimestamped
+	}
+	if nativeLinePattern.MatchString(line) {
+		return FormatNative
+	}
+	return FormatUnknown
+}
+
+// Normalizer converts log lines of any supported format to the timestamped
+// format understood by ParseLine
+//
+// games.log only has the time since the map was loaded, so absolute times
+// continue from the last line seen whenever an InitGame restarts the clock.
+// The clock starts at the g_timestamp serverinfo of an InitGame when the
+// server sets one, and at nativeEpoch otherwise, so reading the same log
+// always gives the same times and round ids. It also has no "Server:" line,
+// so one is added when InitGame loads another map.
+type Normalizer struct {
+	// base is the absolute time the current map was loaded
+	base time.Time
+
+	// last is the absolute time of the last native line
+	last time.Time
+
+	// timestamp is the last g_timestamp seen, which stays the same for
+	// every map until the server is restarted
+	timestamp string
+
+	mapName string
+}
+
+// nativeEpoch is the time a games.log starts at without a g_timestamp
+var nativeEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// NewNormalizer creates a normalizer for a new log
+func NewNormalizer() *Normalizer {
+	return &Normalizer{}
+}
+
+// Normalize returns the timestamped lines for a line of any format
+// Lines in an unknown format are returned as is
+func (n *Normalizer) Normalize(line string) []string {
+	match := nativeLinePattern.FindStringSubmatch(line)
+	if match == nil || HasTimestamp(line) {
+		return []string{line}
+	}
+
+	minutes, _ := strconv.Atoi(match[1])
+	seconds, _ := strconv.Atoi(match[2])
+	offset := time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	text := match[3]
+
+	if n.last.IsZero() {
+		n.last = nativeEpoch
+		n.base = n.last
+	}
+
+	var lines []string
+	if strings.HasPrefix(text, ActionInitGame) {
+		// The clock restarts with every InitGame
+		n.base = n.last.Add(-offset)
+		if timestamp := infoValue(text, "g_timestamp"); timestamp != "" && timestamp != n.timestamp {
+			if t, err := time.Parse(models.TimestampLayout, timestamp); err == nil {
+				n.timestamp = timestamp
+				n.base = t.Add(-offset)
+			}
+		}
+		if mapName := infoValue(text, "mapname"); mapName != "" && mapName != n.mapName {
+			n.mapName = mapName
+			lines = append(lines, AddTimestamp(fmt.Sprintf("%s %s", ActionServer, mapName), n.base.Add(offset)))
+		}
+	}
+
+	n.last = n.base.Add(offset)
+	return append(lines, AddTimestamp(text, n.last))
+}
+
+// infoValue returns the value of key in the infostring of an InitGame line
+func infoValue(text, key string) string {
+	fields := strings.Split(strings.TrimPrefix(text, ActionInitGame+" "), "\\")
+	for i := 1; i+1 < len(fields); i += 2 {
+		if fields[i] == key {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Format
+	}{
+		{line: "2024-04-19 16:01:33 ]\b \bKill: 5 5 20: A killed B by MOD_SUICIDE", expected: FormatTimestamped},
+		{line: "  0:00 InitGame: \\sv_hostname\\Deathquake\\mapname\\q3dm17", expected: FormatNative},
+		{line: " 12:34 Kill: 2 3 10: A killed B by MOD_RAILGUN", expected: FormatNative},
+		{line: "123:05 ShutdownGame:", expected: FormatNative},
+		{line: "]\b \bKill: 2 3 10: A killed B by MOD_RAILGUN", expected: FormatUnknown},
+		{line: "", expected: FormatUnknown},
+	}
+
+	for _, tt := range tests {
+		if result := DetectFormat(tt.line); result != tt.expected {
+			t.Errorf("DetectFormat(%q) = %v, want %v", tt.line, result, tt.expected)
+		}
+	}
+}
+
+func TestNormalizer_Native(t *testing.T) {
+	normalizer := NewNormalizer()
+
+	input := []string{
+		"  0:00 InitGame: \\sv_hostname\\Deathquake\\mapname\\q3dm17\\g_gametype\\0",
+		"  1:05 Kill: 2 3 10: A killed B by MOD_RAILGUN",
+		"  8:00 Exit: Timelimit hit.",
+		"  0:00 InitGame: \\sv_hostname\\Deathquake\\mapname\\q3dm17\\g_gametype\\0",
+		"  0:10 Kill: 3 2 10: B killed A by MOD_RAILGUN",
+		"  0:00 InitGame: \\sv_hostname\\Deathquake\\mapname\\q3dm6\\g_gametype\\0",
+	}
+	expected := []string{
+		"2000-01-01 00:00:00 Server: q3dm17",
+		"2000-01-01 00:00:00 InitGame: \\sv_hostname\\Deathquake\\mapname\\q3dm17\\g_gametype\\0",
+		"2000-01-01 00:01:05 Kill: 2 3 10: A killed B by MOD_RAILGUN",
+		"2000-01-01 00:08:00 Exit: Timelimit hit.",
+		// Restarting the same map does not change the map
+		"2000-01-01 00:08:00 InitGame: \\sv_hostname\\Deathquake\\mapname\\q3dm17\\g_gametype\\0",
+		"2000-01-01 00:08:10 Kill: 3 2 10: B killed A by MOD_RAILGUN",
+		"2000-01-01 00:08:10 Server: q3dm6",
+		"2000-01-01 00:08:10 InitGame: \\sv_hostname\\Deathquake\\mapname\\q3dm6\\g_gametype\\0",
+	}
+
+	var result []string
+	for _, line := range input {
+		result = append(result, normalizer.Normalize(line)...)
+	}
+
+	if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected normalized lines:\n%s\nwant:\n%s", strings.Join(result, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestNormalizer_StartsAtServerTimestamp(t *testing.T) {
+	normalizer := NewNormalizer()
+
+	input := []string{
+		"  0:00 InitGame: \\g_timestamp\\2025-12-05 14:00:00\\mapname\\q3dm17",
+		"  8:00 Exit: Timelimit hit.",
+		// The server keeps the timestamp it was started with
+		"  0:00 InitGame: \\g_timestamp\\2025-12-05 14:00:00\\mapname\\q3dm6",
+		"  0:10 Kill: 3 2 10: B killed A by MOD_RAILGUN",
+		// The server was restarted
+		"  0:00 InitGame: \\g_timestamp\\2025-12-05 19:30:00\\mapname\\q3dm7",
+	}
+	var result []string
+	for _, line := range input {
+		result = append(result, normalizer.Normalize(line)...)
+	}
+
+	for i, expected := range []string{
+		"2025-12-05 14:00:00 Server: q3dm17",
+		"2025-12-05 14:00:00 InitGame:",
+		"2025-12-05 14:08:00 Exit:",
+		"2025-12-05 14:08:00 Server: q3dm6",
+		"2025-12-05 14:08:00 InitGame:",
+		"2025-12-05 14:08:10 Kill:",
+		"2025-12-05 19:30:00 Server: q3dm7",
+	} {
+		if i >= len(result) || !strings.HasPrefix(result[i], expected) {
+			t.Errorf("Line %d: expected %q, got:\n%s", i, expected, strings.Join(result, "\n"))
+		}
+	}
+}
+
+func TestNormalizer_TimestampedUnchanged(t *testing.T) {
+	line := "2024-04-19 16:01:33 ]\b \bKill: 5 5 20: A killed B by MOD_SUICIDE"
+	result := NewNormalizer().Normalize(line)
+	if len(result) != 1 || result[0] != line {
+		t.Errorf("Expected timestamped line to be unchanged, got %q", result)
+	}
+}
+
+// toNative converts the timestamped sample log to the games.log format,
+// with the time relative to the last InitGame and without Server: lines
+func toNative(t *testing.T, fileName string) []string {
+	t.Helper()
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines []string
+	var initGame time.Time
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.Replace(scanner.Text(), "]\b \b", "", 1)
+		timestamp, err := time.Parse(models.TimestampLayout, line[:len(models.TimestampLayout)])
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := line[len(models.TimestampLayout)+1:]
+		if strings.HasPrefix(text, ActionServer) {
+			continue
+		}
+		if strings.HasPrefix(text, ActionInitGame) || initGame.IsZero() {
+			initGame = timestamp
+		}
+		offset := timestamp.Sub(initGame)
+		lines = append(lines, fmt.Sprintf("%3d:%02d %s", int(offset.Minutes()), int(offset.Seconds())%60, text))
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestTailLines_NativeMatchesTimestamped(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}}
+
+	timestamped := models.NewGame(cfg, logger)
+	file, err := os.Open("../samples/f24.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := TailReader(context.Background(), file, nil, timestamped, logger); err != nil {
+		t.Fatal(err)
+	}
+
+	native := models.NewGame(cfg, logger)
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for _, line := range toNative(t, "../samples/f24.txt") {
+			lines <- line
+		}
+	}()
+	if err := TailLines(context.Background(), lines, nil, native, logger); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(timestamped.Rounds) == 0 {
+		t.Fatal("Expected rounds in the sample log")
+	}
+	if len(native.Rounds) != len(timestamped.Rounds) {
+		t.Fatalf("Expected %d rounds from games.log, got %d", len(timestamped.Rounds), len(native.Rounds))
+	}
+	for name, expected := range timestamped.Players {
+		player, ok := native.Players[name]
+		if !ok {
+			t.Errorf("Expected player %s in games.log", name)
+			continue
+		}
+		if player.Kills != expected.Kills || player.Deaths != expected.Deaths || player.Score != expected.Score {
+			t.Errorf("%s: expected %d/%d/%.4f, got %d/%d/%.4f", name,
+				expected.Kills, expected.Deaths, expected.Score, player.Kills, player.Deaths, player.Score)
+		}
+	}
+}
+
+func TestTailLines_NativeRoundIdsAreTheSameEveryRead(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}}
+	native := toNative(t, "../samples/f24.txt")
+
+	// readIds reads the games.log the way a restart does
+	readIds := func() []string {
+		game := models.NewGame(cfg, logger)
+		lines := make(chan string)
+		go func() {
+			defer close(lines)
+			for _, line := range native {
+				lines <- line
+			}
+		}()
+		if err := TailLines(context.Background(), lines, nil, game, logger); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, round := range game.Rounds {
+			ids = append(ids, round.Id)
+		}
+		return ids
+	}
+
+	first := readIds()
+	second := readIds()
+	if len(first) == 0 {
+		t.Fatal("Expected rounds in the sample log")
+	}
+	if strings.Join(first, " ") != strings.Join(second, " ") {
+		t.Errorf("Expected the same round ids every read, got:\n%v\n%v", first, second)
+	}
+	if first[0] != "2000-01-01T00:00:56-q3dm4" {
+		t.Errorf("Expected the round ids to start at the epoch, got %q", first[0])
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"hash/fnv"
+	"log/slog"
+)
+
+// history remembers the lines processed from the log file, so the lines
+// already processed are skipped when the file is reopened and read again
+//
+// A rotated or truncated log is reopened from the start. When it starts with
+// the lines already processed, e.g. a copy of the old log, those lines are
+// skipped and tailing continues after them. Otherwise the file is a new log,
+// e.g. from a restarted server, and is read from the start.
+type history struct {
+	// hashes of the lines processed from the current file, in order
+	hashes []uint64
+
+	// matched is the number of lines read again since the file was
+	// reopened, or -1 when the file wasn't reopened
+	matched int
+}
+
+func newHistory() *history {
+	return &history{matched: -1}
+}
+
+// reopened starts comparing the lines read to the lines already processed
+func (h *history) reopened() {
+	h.matched = 0
+}
+
+// skip records the line and returns true if it was already processed
+// before the file was reopened
+func (h *history) skip(line string, logger *slog.Logger) bool {
+	sum := hashLine(line)
+	if h.matched < 0 {
+		h.hashes = append(h.hashes, sum)
+		return false
+	}
+
+	if h.matched < len(h.hashes) && h.hashes[h.matched] == sum {
+		h.matched++
+		if h.matched == len(h.hashes) {
+			logger.Info("Skipped lines already processed, continuing", "lines", h.matched)
+			h.matched = -1
+		}
+		return true
+	}
+
+	if h.matched == 0 {
+		logger.Info("Log file was replaced, reading the new log from the start")
+	} else {
+		logger.Info("Log file differs from the lines already processed, continuing from there", "lines", h.matched)
+	}
+	h.hashes = append(h.hashes[:h.matched], sum)
+	h.matched = -1
+	return false
+}
+
+func hashLine(line string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(line))
+	return hash.Sum64()
+}
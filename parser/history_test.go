@@ -0,0 +1,232 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+func TestHistory_SkipsLinesAlreadyProcessed(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lines := newHistory()
+	for _, line := range []string{"a", "b", "c"} {
+		if lines.skip(line, logger) {
+			t.Errorf("Expected %q not to be skipped before reopening", line)
+		}
+	}
+
+	lines.reopened()
+	for _, line := range []string{"a", "b", "c"} {
+		if !lines.skip(line, logger) {
+			t.Errorf("Expected %q to be skipped after reopening", line)
+		}
+	}
+	if lines.skip("d", logger) {
+		t.Errorf("Expected a new line not to be skipped")
+	}
+	if lines.skip("a", logger) {
+		t.Errorf("Expected a repeated line not to be skipped without reopening")
+	}
+}
+
+func TestHistory_ReadsNewLogFromStart(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lines := newHistory()
+	lines.skip("a", logger)
+	lines.skip("b", logger)
+
+	lines.reopened()
+	for _, line := range []string{"x", "a", "b"} {
+		if lines.skip(line, logger) {
+			t.Errorf("Expected %q of a new log not to be skipped", line)
+		}
+	}
+
+	// The new log is the one compared to after reopening again
+	lines.reopened()
+	if !lines.skip("x", logger) {
+		t.Errorf("Expected the first line of the new log to be skipped")
+	}
+}
+
+func TestHistory_ContinuesWhereLogDiffers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lines := newHistory()
+	lines.skip("a", logger)
+	lines.skip("b", logger)
+
+	lines.reopened()
+	if !lines.skip("a", logger) {
+		t.Errorf("Expected the matching line to be skipped")
+	}
+	if lines.skip("c", logger) {
+		t.Errorf("Expected the differing line not to be skipped")
+	}
+	if lines.skip("b", logger) {
+		t.Errorf("Expected lines after the difference not to be skipped")
+	}
+}
+
+const (
+	killOne = "2024-04-19 16:02:15 ]\b \bKill: 0 1 10: PlayerOne killed PlayerTwo by MOD_RAILGUN\n"
+	killTwo = "2024-04-19 16:02:16 ]\b \bKill: 0 1 10: PlayerOne killed PlayerTwo by MOD_RAILGUN\n"
+	revenge = "2024-04-19 16:05:00 ]\b \bKill: 1 0 10: PlayerTwo killed PlayerOne by MOD_RAILGUN\n"
+)
+
+// tailFile tails the file in the background until the test ends
+func tailFile(t *testing.T, fileName string) *Recorder {
+	game := newSampleGame()
+	game.IsWarmup = false
+	recorder := &Recorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Tail(ctx, fileName, recorder, game, game.Logger)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return recorder
+}
+
+// waitForKills waits until the player has the number of kills
+func waitForKills(t *testing.T, recorder *Recorder, playerName string, kills int) *models.Snapshot {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if snapshot := recorder.Last(); snapshot != nil {
+			if player := snapshot.Player(playerName); player != nil && player.RoundKills >= kills {
+				return snapshot
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s to have %d kills", playerName, kills)
+	return nil
+}
+
+func appendToFile(t *testing.T, fileName, text string) {
+	t.Helper()
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+}
+
+func TestTail_ContinuesAfterTruncation(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "server.log")
+	appendToFile(t, fileName, killOne+killTwo)
+	recorder := tailFile(t, fileName)
+	waitForKills(t, recorder, "PlayerOne", 2)
+
+	// The server is restarted with the same log name
+	if err := os.Truncate(fileName, 0); err != nil {
+		t.Fatalf("Failed to truncate log: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	appendToFile(t, fileName, revenge)
+
+	snapshot := waitForKills(t, recorder, "PlayerTwo", 1)
+	if kills := snapshot.Player("PlayerOne").RoundKills; kills != 2 {
+		t.Errorf("Expected PlayerOne to still have 2 kills, got %d", kills)
+	}
+}
+
+func TestTail_SkipsLinesReadAgainAfterRotation(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "server.log")
+	appendToFile(t, fileName, killOne+killTwo)
+	recorder := tailFile(t, fileName)
+	waitForKills(t, recorder, "PlayerOne", 2)
+
+	// The log is rotated and a copy of it is written in its place
+	if err := os.Rename(fileName, fileName+".1"); err != nil {
+		t.Fatalf("Failed to rotate log: %v", err)
+	}
+	appendToFile(t, fileName, killOne+killTwo+revenge)
+
+	snapshot := waitForKills(t, recorder, "PlayerTwo", 1)
+	if kills := snapshot.Player("PlayerOne").RoundKills; kills != 2 {
+		t.Errorf("Expected the lines read again not to be counted, PlayerOne has %d kills", kills)
+	}
+}
+
+func TestTail_ReadsNewLogAfterRotation(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "server.log")
+	appendToFile(t, fileName, killOne)
+	recorder := tailFile(t, fileName)
+	waitForKills(t, recorder, "PlayerOne", 1)
+
+	if err := os.Rename(fileName, fileName+".1"); err != nil {
+		t.Fatalf("Failed to rotate log: %v", err)
+	}
+	appendToFile(t, fileName, killTwo+revenge)
+
+	snapshot := waitForKills(t, recorder, "PlayerTwo", 1)
+	if kills := snapshot.Player("PlayerOne").RoundKills; kills != 2 {
+		t.Errorf("Expected PlayerOne to have 2 kills, got %d", kills)
+	}
+}
+
+func TestTail_RotatesWhileLinesArrive(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "server.log")
+	appendToFile(t, fileName, "")
+	recorder := tailFile(t, fileName)
+
+	// Every kill has its own time, so the new log differs from the old one
+	const kills = 200
+	for i := 0; i < kills; i++ {
+		if i == kills/2 {
+			if err := os.Rename(fileName, fileName+".1"); err != nil {
+				t.Fatalf("Failed to rotate log: %v", err)
+			}
+		}
+		appendToFile(t, fileName, fmt.Sprintf("2024-04-19 16:%02d:%02d ]\b \bKill: 0 1 10: PlayerOne killed PlayerTwo by MOD_RAILGUN\n", i/60, i%60))
+		if i%10 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitForKills(t, recorder, "PlayerOne", kills)
+	time.Sleep(3 * pollInterval)
+	if got := recorder.Last().Player("PlayerOne").RoundKills; got != kills {
+		t.Errorf("Expected every line to be counted once, PlayerOne has %d kills", got)
+	}
+}
+
+func TestTail_ParseErrorsHaveLineNumbersOfTheCurrentFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "server.log")
+	appendToFile(t, fileName, killOne+killTwo)
+	recorder := tailFile(t, fileName)
+	waitForKills(t, recorder, "PlayerOne", 2)
+
+	if err := os.Truncate(fileName, 0); err != nil {
+		t.Fatalf("Failed to truncate log: %v", err)
+	}
+	time.Sleep(3 * pollInterval)
+	appendToFile(t, fileName, revenge+"2024-04-19 16:05:01 Item: x weapon_railgun\n")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if snapshot := recorder.Last(); snapshot != nil && len(snapshot.ParseErrorLog) > 0 {
+			if number := snapshot.ParseErrorLog[0].LineNumber; number != 2 {
+				t.Errorf("Expected the error on line 2 of the new file, got line %d", number)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for the parse error")
+}
@@ -1,105 +1,293 @@
 package parser
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/fjerlv/deathquake-go/models"
-	"github.com/fjerlv/deathquake-go/ui"
-	"github.com/hpcloud/tail"
 )
 
 const (
-	ActionKill   = "Kill:"
-	ActionScore  = "score:"
-	ActionServer = "Server:"
+	ActionKill     = "Kill:"
+	ActionScore    = "score:"
+	ActionServer   = "Server:"
+	ActionInitGame = "InitGame:"
+
+	ActionClientConnect         = "ClientConnect:"
+	ActionClientBegin           = "ClientBegin:"
+	ActionClientDisconnect      = "ClientDisconnect:"
+	ActionClientUserinfoChanged = "ClientUserinfoChanged:"
+	ActionItem                  = "Item:"
+	ActionCTF                   = "CTF:"
+	ActionTeamScores            = "red:"
+	ActionSay                   = "say:"
+	ActionSayTeam               = "sayteam:"
 )
 
-func Tail(fileName string, teaProgram *tea.Program, game *models.Game, logger *log.Logger) error {
-	logger.Printf("[TAIL] Starting to tail file: %s", fileName)
-	t, err := tail.TailFile(fileName, tail.Config{Follow: true})
-	if err != nil {
-		logger.Printf("[TAIL] Failed to open file: %v", err)
+// lineBuffer is the number of lines read ahead of the parser
+const lineBuffer = 256
+
+// Tail follows the log file, parsing lines as they are written, until ctx is cancelled
+// The file is reopened when it is truncated, rotated or recreated, without
+// parsing the lines already processed again
+func Tail(ctx context.Context, fileName string, subscriber Subscriber, game *models.Game, logger *slog.Logger) error {
+	logger.Info("Starting to tail file", "file", fileName)
+
+	// Lines are read in the background, so pending updates are sent while
+	// waiting for the next line
+	lines := make(chan fileLine, lineBuffer)
+	followed := make(chan error, 1)
+	go func() {
+		followed <- followFile(ctx, fileName, lines, logger)
+	}()
+
+	normalizer := NewNormalizer()
+	updates := newUpdater(subscriber, game)
+	processed := newHistory()
+	receivingScores := false
+	follow(ctx, lines, updates, func(line fileLine) {
+		if line.reopened {
+			processed.reopened()
+			game.BytesRead = 0
+		}
+		game.BytesRead += int64(len(line.text)) + 1
+		if processed.skip(line.text, logger) {
+			return
+		}
+		receivingScores = processLine(line.text, line.number, normalizer, updates, game, logger, receivingScores)
+	})
+
+	if err := <-followed; err != nil {
+		logger.Error("Failed to tail file", "file", fileName, "error", err)
 		return err
 	}
+	logger.Info("File tail ended", "file", fileName)
+	return nil
+}
+
+// TailLines parses lines received on a channel until it is closed or ctx is cancelled
+// Used to track a server started in the same process without going through a file
+func TailLines(ctx context.Context, lines <-chan string, subscriber Subscriber, game *models.Game, logger *slog.Logger) error {
+	logger.Info("Waiting for lines")
+	normalizer := NewNormalizer()
+	updates := newUpdater(subscriber, game)
+	receivingScores := false
+	lineNumber := 0
+	follow(ctx, lines, updates, func(line string) {
+		lineNumber++
+		receivingScores = processLine(line, lineNumber, normalizer, updates, game, logger, receivingScores)
+		game.BytesRead += int64(len(line)) + 1
+	})
+	logger.Info("Line channel closed")
+	return nil
+}
 
-	logger.Printf("[TAIL] Successfully opened file, waiting for lines...")
+// TailReader parses lines read from r, e.g. stdin, until it is exhausted or ctx is cancelled
+// Lines without a timestamp, like the raw output of ioq3ded, are timestamped when read
+func TailReader(ctx context.Context, r io.Reader, subscriber Subscriber, game *models.Game, logger *slog.Logger) error {
+	logger.Info("Reading lines")
+
+	// Lines are read in the background, so pending updates are sent while
+	// waiting for the next line
+	lines := make(chan string, lineBuffer)
+	scanned := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanned <- scanner.Err()
+	}()
+
+	normalizer := NewNormalizer()
+	updates := newUpdater(subscriber, game)
 	receivingScores := false
-	for line := range t.Lines {
-		if err, receivingScores = ParseLine(line.Text, game, logger, receivingScores); err != nil {
-			logger.Println("[ERROR]", err)
-		}
-		if teaProgram != nil {
-			teaProgram.Send(
-				ui.CreateGameUpdate(
-					ui.GameUpdate{
-						Players: game.GetSortedPlayers(),
-						Game:    game,
-					},
-				),
-			)
-		}
-	}
-	logger.Printf("[TAIL] File tail ended")
+	lineNumber := 0
+	follow(ctx, lines, updates, func(raw string) {
+		lineNumber++
+		line := raw
+		if DetectFormat(line) == FormatUnknown {
+			line = AddTimestamp(line, time.Now())
+		}
+		receivingScores = processLine(line, lineNumber, normalizer, updates, game, logger, receivingScores)
+		game.BytesRead += int64(len(raw)) + 1
+	})
+
+	// Read errors are only known when the reader was read to the end
+	select {
+	case err := <-scanned:
+		if err != nil {
+			return fmt.Errorf("failed to read lines: %w", err)
+		}
+	default:
+	}
+	logger.Info("Reader ended")
 	return nil
 }
 
-func ParseLine(line string, game *models.Game, logger *log.Logger, receivingScores bool) (error, bool) {
+// processLine normalizes and parses a line, and marks the game as changed
+// when the line changed it
+// lineNumber is the number of the line in the file it was read from
+func processLine(line string, lineNumber int, normalizer *Normalizer, updates *updater, game *models.Game, logger *slog.Logger, receivingScores bool) bool {
+	var err error
+	for _, normalized := range normalizer.Normalize(line) {
+		rounds := len(game.Rounds)
+		if err, receivingScores = ParseLine(normalized, game, logger, receivingScores); err != nil {
+			parseErr, ok := err.(*models.ParseError)
+			if !ok {
+				parseErr = models.NewParseError(models.ParseErrorMalformed, normalized, err)
+			}
+			parseErr.LineNumber = lineNumber
+			logger.Warn("Could not parse line", "round_id", game.CurrentRoundId, "category", parseErr.Category,
+				"line_number", parseErr.LineNumber, "line", parseErr.Line, "error", parseErr.Reason)
+			game.RecordParseError(parseErr)
+			updates.changed()
+		} else if changesState(normalized) {
+			updates.changed()
+		}
+
+		// An ended round is sent right away, so subscribers see the standings
+		// after every round even when catching up
+		if len(game.Rounds) != rounds {
+			updates.flush()
+		}
+	}
+	game.LinesProcessed++
+	return receivingScores
+}
+
+// ParseLine parses a log line into the game
+// Lines that could not be parsed return a *models.ParseError
+func ParseLine(line string, game *models.Game, logger *slog.Logger, receivingScores bool) (error, bool) {
+	raw := line
 	line = strings.Replace(line, "]\b \b", "", 1)
 	messageSplit := strings.Split(line, " ")
 
 	// Validate line format - need at least 3 parts
 	if len(messageSplit) < 3 {
-		logger.Printf("[%s] [PARSE] Invalid line format (too few parts): %q", game.CurrentRoundId, line)
-		return fmt.Errorf("invalid log line format: expected at least 3 parts, got %d: %q", len(messageSplit), line), receivingScores
+		logger.Debug("Invalid line format, too few parts", "round_id", game.CurrentRoundId, "line", line)
+		err := fmt.Errorf("invalid log line format: expected at least 3 parts, got %d: %q", len(messageSplit), line)
+		return models.NewParseError(models.ParseErrorMalformed, raw, err), receivingScores
 	}
 
 	timestamp := messageSplit[0] + " " + messageSplit[1]
 	action := messageSplit[2]
+	game.CurrentTime = timestamp
+
+	// A flag pickup without a CTF: line right after it is tracked from the Item: line
+	if action != ActionCTF {
+		game.CommitFlagPickup()
+	}
 
 	// Handle kill action
 	if action == ActionKill {
 		attackerName, victimName, weapon := parseKillEvent(messageSplit)
 		if err := validateActionKill(line, attackerName, victimName); err != nil {
-			logger.Printf("[%s] [PARSE] Kill validation failed: %v", game.CurrentRoundId, err)
-			return err, receivingScores
+			logger.Debug("Kill validation failed", "round_id", game.CurrentRoundId, "player", attackerName, "victim", victimName, "weapon", weapon, "error", err)
+			return models.NewParseError(models.ParseErrorKill, raw, err), receivingScores
 		}
 
 		game.RecordKill(attackerName, victimName, weapon)
+	} else if action == ActionInitGame {
+		gameType, err := strconv.Atoi(infoValue(strings.Join(messageSplit[2:], " "), "g_gametype"))
+		if err != nil {
+			gameType = models.GameTypeFFA
+		}
+		logger.Debug("Game initialized", "round_id", game.CurrentRoundId, "game_type", gameType)
+		game.SetGameType(gameType)
+	} else if action == ActionClientUserinfoChanged {
+		info, err := parseUserinfoChanged(messageSplit)
+		if err != nil {
+			logger.Debug("Userinfo validation failed", "round_id", game.CurrentRoundId, "error", err)
+			return models.NewParseError(models.ParseErrorUserinfo, raw, err), receivingScores
+		}
+		game.SetClientName(info.clientId, info.playerName)
+		game.SetPlayerBot(info.playerName, info.isBot)
+		game.SetPlayerTeam(info.playerName, info.team)
+	} else if action == ActionClientConnect || action == ActionClientBegin || action == ActionClientDisconnect {
+		if len(messageSplit) < 4 {
+			err := fmt.Errorf("invalid %s line: expected client number: %q", action, line)
+			return models.NewParseError(models.ParseErrorClient, raw, err), receivingScores
+		}
+		clientId, err := strconv.Atoi(messageSplit[3])
+		if err != nil {
+			err = fmt.Errorf("invalid %s client number %q: %w", action, messageSplit[3], err)
+			return models.NewParseError(models.ParseErrorClient, raw, err), receivingScores
+		}
+		switch action {
+		case ActionClientConnect:
+			game.ClientConnect(clientId)
+		case ActionClientBegin:
+			game.ClientBegin(clientId)
+		case ActionClientDisconnect:
+			game.ClientDisconnect(clientId)
+		}
+	} else if action == ActionItem {
+		if err := parseItemPickup(messageSplit, game); err != nil {
+			logger.Debug("Item validation failed", "round_id", game.CurrentRoundId, "error", err)
+			return models.NewParseError(models.ParseErrorItem, raw, err), receivingScores
+		}
+	} else if action == ActionCTF {
+		playerName, event, err := parseCTFEvent(messageSplit, game)
+		if err != nil {
+			logger.Debug("CTF validation failed", "round_id", game.CurrentRoundId, "error", err)
+			return models.NewParseError(models.ParseErrorCTF, raw, err), receivingScores
+		}
+		game.RecordFlag(playerName, event)
+	} else if action == ActionSay || action == ActionSayTeam {
+		playerName, message, err := parseChat(messageSplit)
+		if err != nil {
+			logger.Debug("Chat validation failed", "round_id", game.CurrentRoundId, "error", err)
+			return models.NewParseError(models.ParseErrorChat, raw, err), receivingScores
+		}
+		game.RecordChat(playerName, message, action == ActionSayTeam)
+	} else if strings.HasPrefix(action, ActionTeamScores) {
+		red, blue, err := parseTeamScores(strings.Join(messageSplit[2:], " "))
+		if err != nil {
+			logger.Debug("Team score validation failed", "round_id", game.CurrentRoundId, "error", err)
+			return models.NewParseError(models.ParseErrorTeamScores, raw, err), receivingScores
+		}
+		game.SetTeamScores(red, blue)
 	} else if action == ActionServer {
 		// Handle server/map change
 		if len(messageSplit) >= 4 {
 			newMapName := messageSplit[3]
-			logger.Printf("[%s] [PARSE] Server map change to: %s", game.CurrentRoundId, newMapName)
+			logger.Debug("Server map change", "round_id", game.CurrentRoundId, "map", newMapName)
 			game.NewMap(newMapName, timestamp)
 		} else {
-			logger.Printf("[%s] [PARSE] Server action with insufficient data: %q", game.CurrentRoundId, line)
+			logger.Debug("Server action with insufficient data", "round_id", game.CurrentRoundId, "line", line)
 		}
 	}
 
 	// Update score state (handles both receiving and ending scores)
 	if action == ActionScore {
-		logger.Printf("[%s] [PARSE] Score action detected (receivingScores: %v, warmup: %v)", game.CurrentRoundId, receivingScores, game.IsWarmup)
-		// First time receiving score line - save the round
+		logger.Debug("Score action detected", "round_id", game.CurrentRoundId, "receiving_scores", receivingScores, "warmup", game.IsWarmup)
+		// First time receiving score line - end the round
 		if !receivingScores && !game.IsWarmup {
 			receivingScores = true
-			if !game.IsSkipped() {
-				logger.Printf("[%s] [PARSE] Saving round (not skipped)", game.CurrentRoundId)
-				game.Save()
-			} else {
-				logger.Printf("[%s] [PARSE] Skipping round save (round is in ignored list)", game.CurrentRoundId)
-			}
+			logger.Debug("Ending round", "round_id", game.CurrentRoundId, "map", game.CurrentMapName)
+			game.EndRound(true)
 		} else if receivingScores {
-			logger.Printf("[%s] [PARSE] Already receiving scores, continuing...", game.CurrentRoundId)
+			logger.Debug("Already receiving scores", "round_id", game.CurrentRoundId)
 		} else if game.IsWarmup {
-			logger.Printf("[%s] [PARSE] Score during warmup, not saving", game.CurrentRoundId)
+			logger.Debug("Score during warmup, not saving", "round_id", game.CurrentRoundId)
 		}
 	} else {
 		// If we were receiving scores and now got a different action, scores have ended
 		if receivingScores {
-			logger.Printf("[%s] [PARSE] Scores ended, returning to normal parsing", game.CurrentRoundId)
+			logger.Debug("Scores ended, returning to normal parsing", "round_id", game.CurrentRoundId)
 			receivingScores = false
 		}
 	}
@@ -168,3 +356,120 @@ func parseKillEvent(messageSplit []string) (attackerName, victimName, weapon str
 
 	return attackerName, victimName, weapon
 }
+
+var teamScoresPattern = regexp.MustCompile(`^red:(-?\d+)\s+blue:(-?\d+)`)
+
+// userinfo is what the parser uses from a ClientUserinfoChanged line
+type userinfo struct {
+	clientId   int
+	playerName string
+	team       models.Team
+	isBot      bool
+}
+
+// parseUserinfoChanged parses "ClientUserinfoChanged: 2 n\Name\t\1\model\..."
+// Bots have a skill key and no id key in their infostring
+func parseUserinfoChanged(messageSplit []string) (userinfo, error) {
+	if len(messageSplit) < 5 {
+		return userinfo{}, fmt.Errorf("invalid userinfo: expected client number and infostring, got %q", strings.Join(messageSplit, " "))
+	}
+	clientId, err := strconv.Atoi(messageSplit[3])
+	if err != nil {
+		return userinfo{}, fmt.Errorf("invalid userinfo client number %q: %w", messageSplit[3], err)
+	}
+
+	// Names may contain spaces, so the infostring is everything after the client number
+	info := strings.Split(strings.Join(messageSplit[4:], " "), "\\")
+	result := userinfo{clientId: clientId}
+	var hasSkill, hasId bool
+	for i := 0; i+1 < len(info); i += 2 {
+		switch info[i] {
+		case "n":
+			result.playerName = info[i+1]
+		case "t":
+			if value, err := strconv.Atoi(info[i+1]); err == nil {
+				result.team = models.Team(value)
+			}
+		case "skill":
+			hasSkill = true
+		case "id":
+			hasId = true
+		}
+	}
+	if result.playerName == "" {
+		return userinfo{}, fmt.Errorf("invalid userinfo: no player name in %q", strings.Join(messageSplit[4:], " "))
+	}
+	result.isBot = hasSkill && !hasId
+	return result, nil
+}
+
+// parseItemPickup records "Item: 2 weapon_railgun" lines, with the client
+// number resolved to the player name
+func parseItemPickup(messageSplit []string, game *models.Game) error {
+	if len(messageSplit) < 5 {
+		return fmt.Errorf("invalid item pickup: expected client number and item, got %q", strings.Join(messageSplit, " "))
+	}
+
+	clientId, err := strconv.Atoi(messageSplit[3])
+	if err != nil {
+		return fmt.Errorf("invalid item client number %q: %w", messageSplit[3], err)
+	}
+	playerName := game.ClientName(clientId)
+	if playerName == "" {
+		return fmt.Errorf("item picked up by unknown client %d", clientId)
+	}
+
+	item := messageSplit[4]
+	switch item {
+	case "team_CTF_redflag":
+		game.PickUpFlag(playerName, models.TeamRed)
+	case "team_CTF_blueflag":
+		game.PickUpFlag(playerName, models.TeamBlue)
+	default:
+		game.RecordItem(playerName, item)
+	}
+	return nil
+}
+
+// parseCTFEvent parses "CTF: 2 1 1: Name captured the BLUE flag!" into the
+// player name and the flag event
+func parseCTFEvent(messageSplit []string, game *models.Game) (string, int, error) {
+	if len(messageSplit) < 6 {
+		return "", 0, fmt.Errorf("invalid CTF event: expected client, team and event, got %q", strings.Join(messageSplit, " "))
+	}
+	clientId, err := strconv.Atoi(messageSplit[3])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid CTF client number %q: %w", messageSplit[3], err)
+	}
+	event, err := strconv.Atoi(strings.TrimSuffix(messageSplit[5], ":"))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid CTF event %q: %w", messageSplit[5], err)
+	}
+
+	playerName := game.ClientName(clientId)
+	if playerName == "" {
+		return "", 0, fmt.Errorf("CTF event by unknown client %d", clientId)
+	}
+	return playerName, event, nil
+}
+
+// parseChat parses "say: Name: message" into the player name and message
+func parseChat(messageSplit []string) (string, string, error) {
+	text := strings.Join(messageSplit[3:], " ")
+	playerName, message, ok := strings.Cut(text, ": ")
+	if !ok || playerName == "" {
+		return "", "", fmt.Errorf("invalid chat message: %q", text)
+	}
+	return playerName, message, nil
+}
+
+// parseTeamScores parses "red:8  blue:5"
+func parseTeamScores(text string) (int, int, error) {
+	match := teamScoresPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, 0, fmt.Errorf("invalid team scores: %q", text)
+	}
+	red, _ := strconv.Atoi(match[1])
+	blue, _ := strconv.Atoi(match[2])
+	return red, blue, nil
+}
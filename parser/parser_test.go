@@ -2,11 +2,16 @@ package parser
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,7 +25,7 @@ func TestParseLine_KillCreatesPlayers(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -72,7 +77,7 @@ func TestParseLine_KillsDuringWarmupNotRegistered(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players:  make(map[string]*models.Player),
 		Config:   cfg,
@@ -114,7 +119,7 @@ func TestParseLine_WorldKill(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -154,7 +159,7 @@ func TestParseLine_Suicide(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -193,7 +198,7 @@ func TestParseLine_PlasmaWeapon(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -233,7 +238,7 @@ func TestParseLine_RocketWeapon(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -264,7 +269,7 @@ func TestParseLine_RocketSplashWeapon(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -291,7 +296,7 @@ func TestParseLine_GauntletWeapon(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -318,7 +323,7 @@ func TestParseLine_MapChange(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -351,7 +356,7 @@ func TestParseLine_MultipleMapChanges(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -388,7 +393,7 @@ func TestParseLine_ScoreAction(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -433,7 +438,7 @@ func TestParseLine_InvalidLine(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -456,7 +461,7 @@ func TestParseLine_MultiWordPlayerNames(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -484,7 +489,7 @@ func TestParseLine_PlayerNameContainsKilled(t *testing.T) {
 		IgnoredPlayers:       []string{},
 		DrinkingCiderPlayers: []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -515,7 +520,7 @@ func TestParseLine_PlayerNameContainsKilled(t *testing.T) {
 }
 
 func TestParseLine_SkipGames(t *testing.T) {
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	// Test 1: Game in skip list should NOT save rounds when score is posted
 	cfg := &config.Config{
@@ -556,7 +561,7 @@ func TestParseLine_SkipGames(t *testing.T) {
 	scoreLine := "2025-12-05 16:02:00 score: 10"
 	_, _ = ParseLine(scoreLine, game, logger, false)
 
-	// Verify that SaveRound was NOT called by checking that players don't have saved rounds
+	// Verify that Save was NOT called by checking that players don't have saved rounds
 	// (checking Score which would be > 0 if rounds were saved)
 	for _, p := range game.Players {
 		if p.Score > 0 {
@@ -757,7 +762,7 @@ func TestSetRanks(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			logger := log.New(io.Discard, "", 0)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 			game := &models.Game{Players: tt.players, Logger: logger}
 			playerSlice := make([]*models.Player, 0, len(game.Players))
 			for _, p := range game.Players {
@@ -857,7 +862,7 @@ func TestGetSortedPlayers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			logger := log.New(io.Discard, "", 0)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 			game := &models.Game{Players: tt.players, Logger: logger}
 			result := game.GetSortedPlayers()
 
@@ -913,7 +918,7 @@ func TestGetFragLimit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			logger := log.New(io.Discard, "", 0)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 			game := &models.Game{Players: tt.players, Logger: logger}
 			result := game.GetFragLimit()
 			if result != tt.expected {
@@ -1053,7 +1058,7 @@ func TestParseLine_ReturnsErrorWhenAttackerNameContainsKilled(t *testing.T) {
 		DrinkingCiderPlayers: []string{},
 		IgnoredRounds:           []string{},
 	}
-	logger := log.New(io.Discard, "", 0)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	game := &models.Game{
 		Players: make(map[string]*models.Player),
 		Config:  cfg,
@@ -1095,9 +1100,9 @@ func TestTail_LogsErrorFromParseLine(t *testing.T) {
 	}
 	tmpFile.Close()
 
-	// Create a buffer to capture logger output
-	var logBuf bytes.Buffer
-	logger := log.New(&logBuf, "", 0)
+	// Create a buffer to capture logger output, written by the Tail goroutine
+	var logBuf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&logBuf, nil))
 
 	cfg := &config.Config{
 		IgnoredPlayers:       []string{},
@@ -1117,7 +1122,7 @@ func TestTail_LogsErrorFromParseLine(t *testing.T) {
 
 	// Start tailing in a goroutine
 	go func() {
-		Tail(tmpFile.Name(), nil, game, logger)
+		Tail(context.Background(), tmpFile.Name(), nil, game, logger)
 	}()
 
 	// Wait for processing
@@ -1125,8 +1130,8 @@ func TestTail_LogsErrorFromParseLine(t *testing.T) {
 
 	// Verify that the error was logged
 	logOutput := logBuf.String()
-	if !strings.Contains(logOutput, "[ERROR]") {
-		t.Errorf("Expected logger to contain '[ERROR]', got: %q", logOutput)
+	if !strings.Contains(logOutput, `level=WARN msg="Could not parse line"`) {
+		t.Errorf("Expected logger to contain the parse warning, got: %q", logOutput)
 	}
 
 	expectedErrMsg := "invalid kill event: line contains 'killed' 2 times"
@@ -1134,3 +1139,375 @@ func TestTail_LogsErrorFromParseLine(t *testing.T) {
 		t.Errorf("Expected logger to contain %q, got: %q", expectedErrMsg, logOutput)
 	}
 }
+
+func TestAddTimestamp(t *testing.T) {
+	timestamp := time.Date(2025, 12, 5, 14, 23, 45, 0, time.Local)
+	line := AddTimestamp("]\b \bKill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN", timestamp)
+
+	expected := "2025-12-05 14:23:45 ]\b \bKill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN"
+	if line != expected {
+		t.Errorf("Expected %q, got %q", expected, line)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{
+		IgnoredPlayers:       []string{},
+		DrinkingCiderPlayers: []string{},
+	}
+	game := models.NewGame(cfg, logger)
+	game.IsWarmup = false
+
+	lines := make(chan string, 2)
+	timestamp := time.Date(2025, 12, 5, 14, 23, 45, 0, time.Local)
+	lines <- AddTimestamp("Kill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN", timestamp)
+	lines <- AddTimestamp("Kill: 2 3 10: PlayerTwo killed PlayerOne by MOD_RAILGUN", timestamp)
+	close(lines)
+
+	if err := TailLines(context.Background(), lines, nil, game, logger); err != nil {
+		t.Fatalf("TailLines failed: %v", err)
+	}
+
+	if game.LinesProcessed != 2 {
+		t.Errorf("Expected 2 lines processed, got %d", game.LinesProcessed)
+	}
+	if game.Players["PlayerOne"].RoundKills != 1 || game.Players["PlayerTwo"].RoundKills != 1 {
+		t.Errorf("Expected both players to have 1 kill")
+	}
+}
+
+func TestHasTimestamp(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{line: "2025-12-05 14:23:45 Kill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN", expected: true},
+		{line: "2025-12-05 14:23:45 ]\b \bShutdownGame:", expected: true},
+		{line: "]\b \bKill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN", expected: false},
+		{line: "Kill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN", expected: false},
+		{line: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		if result := HasTimestamp(tt.line); result != tt.expected {
+			t.Errorf("HasTimestamp(%q) = %v, want %v", tt.line, result, tt.expected)
+		}
+	}
+}
+
+func TestTailReader_TimestampsRawLines(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{
+		IgnoredPlayers:       []string{},
+		DrinkingCiderPlayers: []string{},
+	}
+	game := models.NewGame(cfg, logger)
+	game.IsWarmup = false
+
+	// Raw ioq3ded output mixed with an already timestamped line
+	input := "]\b \bKill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN\n" +
+		"2025-12-05 14:23:45 Kill: 2 3 10: PlayerTwo killed PlayerOne by MOD_RAILGUN\n"
+
+	before := time.Now().Truncate(time.Second)
+	if err := TailReader(context.Background(), strings.NewReader(input), nil, game, logger); err != nil {
+		t.Fatalf("TailReader failed: %v", err)
+	}
+
+	if game.LinesProcessed != 2 || game.ParseErrors != 0 {
+		t.Errorf("Expected 2 lines without errors, got %d lines and %d errors", game.LinesProcessed, game.ParseErrors)
+	}
+	if len(game.KillFeed) != 2 {
+		t.Fatalf("Expected 2 kills, got %d", len(game.KillFeed))
+	}
+
+	timestamp, err := time.ParseInLocation(models.TimestampLayout, game.KillFeed[0].Timestamp, time.Local)
+	if err != nil || timestamp.Before(before) {
+		t.Errorf("Expected raw line to be timestamped now, got %q", game.KillFeed[0].Timestamp)
+	}
+	if game.KillFeed[1].Timestamp != "2025-12-05 14:23:45" {
+		t.Errorf("Expected existing timestamp to be kept, got %q", game.KillFeed[1].Timestamp)
+	}
+}
+
+func TestParseLine_TeamGame(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}}
+	game := models.NewGame(cfg, logger)
+
+	lines := []string{
+		"2025-12-05 14:00:00 Server: q3ctf1",
+		"2025-12-05 14:00:00 Server: q3ctf2",
+		"2025-12-05 14:00:00 InitGame: \\capturelimit\\8\\g_gametype\\4\\mapname\\q3ctf2",
+		"2025-12-05 14:00:01 ClientUserinfoChanged: 0 n\\Red Player\\t\\1\\model\\sarge",
+		"2025-12-05 14:00:01 ClientUserinfoChanged: 1 n\\BluePlayer\\t\\2\\model\\visor",
+		"2025-12-05 14:00:10 Item: 0 team_CTF_blueflag",
+		"2025-12-05 14:00:20 CTF: 0 2 0: Red Player got the BLUE flag!",
+		"2025-12-05 14:00:30 CTF: 0 2 1: Red Player captured the BLUE flag!",
+		"2025-12-05 14:00:40 CTF: 1 2 2: BluePlayer returned the BLUE flag!",
+		"2025-12-05 14:00:50 Kill: 0 1 10: Red Player killed BluePlayer by MOD_RAILGUN",
+		"2025-12-05 14:08:00 Exit: Capturelimit hit.",
+		"2025-12-05 14:08:00 red:1  blue:0",
+		"2025-12-05 14:08:00 score: 5  ping: 0  client: 0 Red Player",
+	}
+	for _, line := range lines {
+		if err, _ := ParseLine(line, game, logger, false); err != nil {
+			t.Fatalf("ParseLine(%q) failed: %v", line, err)
+		}
+	}
+
+	if game.GameType != models.GameTypeCTF {
+		t.Errorf("Expected CTF game type, got %d", game.GameType)
+	}
+
+	red := game.Players["Red Player"]
+	if red == nil || red.Team != models.TeamRed {
+		t.Fatalf("Expected Red Player on the red team, got %+v", red)
+	}
+	if red.Captures != 1 || red.FlagTakes != 1 {
+		t.Errorf("Expected 1 capture and 1 take from CTF lines, got %d and %d", red.Captures, red.FlagTakes)
+	}
+	if game.Players["BluePlayer"].FlagReturns != 1 {
+		t.Errorf("Expected BluePlayer to return the flag, got %d", game.Players["BluePlayer"].FlagReturns)
+	}
+
+	if len(game.Rounds) != 1 || game.Rounds[0].RedScore != 1 || game.Rounds[0].BlueScore != 0 {
+		t.Fatalf("Expected a saved round with red 1, blue 0, got %+v", game.Rounds)
+	}
+	if !red.IsRoundWinner() || game.Players["BluePlayer"].Diff != 0 {
+		t.Errorf("Expected red team to win the round, got red %.2f and blue %.2f", red.Diff, game.Players["BluePlayer"].Diff)
+	}
+}
+
+func TestParseTeamScores(t *testing.T) {
+	red, blue, err := parseTeamScores("red:8  blue:5")
+	if err != nil || red != 8 || blue != 5 {
+		t.Errorf("Expected 8 and 5, got %d and %d (%v)", red, blue, err)
+	}
+
+	if _, _, err := parseTeamScores("red:eight"); err == nil {
+		t.Error("Expected error for invalid team scores")
+	}
+}
+
+func TestParseLine_ItemPickupResolvesClient(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{}, logger)
+	game.IsWarmup = false
+
+	lines := []string{
+		"2025-12-05 14:00:01 ClientUserinfoChanged: 4 n\\PlayerOne\\t\\0\\model\\sarge",
+		"2025-12-05 14:00:02 Item: 4 item_quad",
+		"2025-12-05 14:00:03 Item: 4 weapon_plasmagun",
+	}
+	for _, line := range lines {
+		if err, _ := ParseLine(line, game, logger, false); err != nil {
+			t.Fatalf("ParseLine(%q) failed: %v", line, err)
+		}
+	}
+
+	player := game.Players["PlayerOne"]
+	if player == nil || player.RoundItems[models.ItemQuad] != 1 || player.RoundItems["weapon_plasmagun"] != 1 {
+		t.Errorf("Expected PlayerOne to have picked up a quad and a plasma gun, got %+v", player)
+	}
+
+	if err, _ := ParseLine("2025-12-05 14:00:04 Item: 7 item_quad", game, logger, false); err == nil {
+		t.Error("Expected error for an item picked up by an unknown client")
+	}
+}
+
+func TestParseLine_Chat(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{}, logger)
+
+	lines := []string{
+		"2024-04-19 16:02:55 ]\b \bsay: fjerlv: cg_drawTimer 1",
+		"2024-04-19 16:02:56 sayteam: Red Player: go  for the flag: now",
+	}
+	for _, line := range lines {
+		if err, _ := ParseLine(line, game, logger, false); err != nil {
+			t.Fatalf("ParseLine(%q) failed: %v", line, err)
+		}
+	}
+
+	expected := []models.ChatMessage{
+		{Timestamp: "2024-04-19 16:02:55", Player: "fjerlv", Message: "cg_drawTimer 1"},
+		{Timestamp: "2024-04-19 16:02:56", Player: "Red Player", Message: "go  for the flag: now", TeamOnly: true},
+	}
+	if !reflect.DeepEqual(game.ChatLog, expected) {
+		t.Errorf("Expected chat log %+v, got %+v", expected, game.ChatLog)
+	}
+
+	if err, _ := ParseLine("2024-04-19 16:02:57 say: no separator", game, logger, false); err == nil {
+		t.Error("Expected error for a chat line without a player name")
+	}
+}
+
+func TestParseUserinfoChanged_DetectsBots(t *testing.T) {
+	tests := []struct {
+		line  string
+		isBot bool
+	}{
+		{`2024-04-19 16:01:21 ClientUserinfoChanged: 0 n\Mr.Chinaman\t\0\model\slash/yuriko\hmodel\slash/yuriko\g_redteam\\g_blueteam\\c1\3\c2\5\hc\100\w\0\l\0\tt\0\tl\0`, false},
+		{`2024-04-19 16:01:21 ClientUserinfoChanged: 5 n\Sarge\t\0\model\sarge\hmodel\sarge\c1\4\c2\5\hc\70\w\0\l\0\skill\ 3.00\tt\0\tl\0`, true},
+		{`2024-04-19 16:01:21 ClientUserinfoChanged: 6 n\Player\t\0\model\sarge\skill\3\id\ABC123`, false},
+	}
+	for _, tt := range tests {
+		info, err := parseUserinfoChanged(strings.Split(tt.line, " "))
+		if err != nil {
+			t.Fatalf("parseUserinfoChanged(%q) failed: %v", tt.line, err)
+		}
+		if info.isBot != tt.isBot {
+			t.Errorf("Expected %s to be a bot: %v, got %v", info.playerName, tt.isBot, info.isBot)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer that can be written and read concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTail_StopsWhenCancelled(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_cancel_*.log")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.WriteString("2025-12-05 14:00:00 Server: q3dm1\n")
+	tmpFile.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{}, logger)
+	recorder := &Recorder{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Tail(ctx, tmpFile.Name(), recorder, game, logger)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected Tail to stop without error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Tail to stop when cancelled")
+	}
+
+	if last := recorder.Last(); last == nil || last.CurrentMapName != "q3dm1" {
+		t.Errorf("Expected the update for the line read before cancelling, got %+v", last)
+	}
+}
+
+func TestTailReader_StopsWhenCancelled(t *testing.T) {
+	// A pipe that is never written to, like an idle stdin
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{}, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := TailReader(ctx, reader, nil, game, logger); err != nil {
+		t.Errorf("Expected TailReader to stop without error, got %v", err)
+	}
+}
+
+func TestTailReader_RecordsParseErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{}, logger)
+	game.IsWarmup = false
+
+	input := "2025-12-05 14:30:20 Kill: 3 2 10: PlayerOne killed PlayerTwo by MOD_RAILGUN\n" +
+		"2025-12-05 14:30:22 Kill: 4 3 9: killedPlayer killed Victim by MOD_PLASMA\n" +
+		"2025-12-05 14:30:23 say: no separator\n"
+	if err := TailReader(context.Background(), strings.NewReader(input), nil, game, logger); err != nil {
+		t.Fatalf("TailReader failed: %v", err)
+	}
+
+	if game.ParseErrors != 2 {
+		t.Fatalf("Expected 2 parse errors, got %d", game.ParseErrors)
+	}
+	if game.ParseErrorCounts[models.ParseErrorKill] != 1 || game.ParseErrorCounts[models.ParseErrorChat] != 1 {
+		t.Errorf("Unexpected counts per category: %v", game.ParseErrorCounts)
+	}
+	kill := game.ParseErrorLog[0]
+	if kill.Category != models.ParseErrorKill || kill.LineNumber != 2 || !strings.Contains(kill.Line, "killedPlayer") {
+		t.Errorf("Unexpected kill parse error: %+v", kill)
+	}
+	if chat := game.ParseErrorLog[1]; chat.Category != models.ParseErrorChat || chat.LineNumber != 3 {
+		t.Errorf("Unexpected chat parse error: %+v", chat)
+	}
+}
+
+func TestParseLine_ReturnsParseError(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{}, logger)
+
+	line := "2025-12-05 14:30:22 Item: x weapon_railgun"
+	err, _ := ParseLine(line, game, logger, false)
+
+	var parseErr *models.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a *models.ParseError, got %T", err)
+	}
+	if parseErr.Category != models.ParseErrorItem || parseErr.Line != line {
+		t.Errorf("Unexpected parse error: %+v", parseErr)
+	}
+}
+
+func TestTailReader_SameLogTwiceKeepsRatings(t *testing.T) {
+	data, err := os.ReadFile("../samples/f24.txt")
+	if err != nil {
+		t.Fatalf("Failed to read sample: %v", err)
+	}
+	cfg := &config.Config{IgnoredPlayers: []string{"<world>"}, RatingsFile: filepath.Join(t.TempDir(), "ratings.json")}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// readLog reads the log the way a restart does, seeding from the ratings file
+	readLog := func() *models.Ratings {
+		ratings, err := models.LoadRatings(cfg.RatingsFile)
+		if err != nil {
+			t.Fatalf("LoadRatings failed: %v", err)
+		}
+		game := models.NewGame(cfg, logger)
+		game.Ratings = ratings
+		if err := TailReader(context.Background(), bytes.NewReader(data), nil, game, logger); err != nil {
+			t.Fatalf("TailReader failed: %v", err)
+		}
+		return game.Ratings
+	}
+
+	first := readLog()
+	second := readLog()
+	if len(first.Players) == 0 {
+		t.Fatal("Expected ratings after reading the log")
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected reading the log again not to change the ratings")
+		for name, rating := range first.Players {
+			if *second.Players[name] != *rating {
+				t.Errorf("%s: %+v, then %+v", name, *rating, *second.Players[name])
+			}
+		}
+	}
+}
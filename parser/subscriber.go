@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// Subscriber receives the game state whenever it changed while tailing
+// The snapshot is never modified, so it can be kept and read from any goroutine
+type Subscriber interface {
+	OnUpdate(snapshot *models.Snapshot)
+}
+
+// statusSubscriber is a subscriber that also receives the polled server status
+type statusSubscriber interface {
+	OnServerStatus(status *models.ServerStatus)
+}
+
+// Subscribers fans out every update to all of its subscribers
+type Subscribers []Subscriber
+
+func (s Subscribers) OnUpdate(snapshot *models.Snapshot) {
+	for _, subscriber := range s {
+		subscriber.OnUpdate(snapshot)
+	}
+}
+
+// OnServerStatus forwards the server status to the subscribers that want it
+func (s Subscribers) OnServerStatus(status *models.ServerStatus) {
+	for _, subscriber := range s {
+		if subscriber, ok := subscriber.(statusSubscriber); ok {
+			subscriber.OnServerStatus(status)
+		}
+	}
+}
+
+// Recorder is a subscriber that keeps every update, e.g. to inspect the
+// game after tailing
+type Recorder struct {
+	mu        sync.Mutex
+	snapshots []*models.Snapshot
+}
+
+func (r *Recorder) OnUpdate(snapshot *models.Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.snapshots = append(r.snapshots, snapshot)
+}
+
+// Snapshots returns the updates received so far, oldest first
+func (r *Recorder) Snapshots() []*models.Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.Snapshot(nil), r.snapshots...)
+}
+
+// Last returns the latest update, or nil if there was none
+func (r *Recorder) Last() *models.Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.snapshots) == 0 {
+		return nil
+	}
+	return r.snapshots[len(r.snapshots)-1]
+}
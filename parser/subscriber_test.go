@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// statusRecorder is a recorder that also receives the server status
+type statusRecorder struct {
+	Recorder
+	statuses []*models.ServerStatus
+}
+
+func (r *statusRecorder) OnServerStatus(status *models.ServerStatus) {
+	r.statuses = append(r.statuses, status)
+}
+
+func TestSubscribers_FanOut(t *testing.T) {
+	plain := &Recorder{}
+	withStatus := &statusRecorder{}
+	subscribers := Subscribers{plain, withStatus}
+
+	snapshot := newSampleGame().Snapshot()
+	subscribers.OnUpdate(snapshot)
+	if plain.Last() != snapshot || withStatus.Last() != snapshot {
+		t.Error("Expected every subscriber to receive the update")
+	}
+
+	status := &models.ServerStatus{MapName: "q3dm17"}
+	subscribers.OnServerStatus(status)
+	if len(withStatus.statuses) != 1 || withStatus.statuses[0] != status {
+		t.Errorf("Expected the status subscriber to receive the status, got %v", withStatus.statuses)
+	}
+}
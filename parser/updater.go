@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// frameInterval bounds how often updates are sent, so catching up on a long
+// log doesn't send an update for every line
+const frameInterval = time.Second / 30
+
+// trackedActions are the actions that change the game state
+var trackedActions = map[string]bool{
+	ActionKill:                  true,
+	ActionScore:                 true,
+	ActionServer:                true,
+	ActionInitGame:              true,
+	ActionClientConnect:         true,
+	ActionClientBegin:           true,
+	ActionClientDisconnect:      true,
+	ActionClientUserinfoChanged: true,
+	ActionItem:                  true,
+	ActionCTF:                   true,
+	ActionSay:                   true,
+	ActionSayTeam:               true,
+}
+
+// changesState returns true if parsing the line can change the game state
+func changesState(line string) bool {
+	fields := strings.SplitN(strings.Replace(line, "]\b \b", "", 1), " ", 4)
+	if len(fields) < 3 {
+		return false
+	}
+	return trackedActions[fields[2]] || strings.HasPrefix(fields[2], ActionTeamScores)
+}
+
+// updater coalesces the changes to the game into at most one update per frame
+type updater struct {
+	subscriber Subscriber
+	game       *models.Game
+	pending    bool
+}
+
+func newUpdater(subscriber Subscriber, game *models.Game) *updater {
+	return &updater{subscriber: subscriber, game: game}
+}
+
+// changed marks the game as changed since the last update
+func (u *updater) changed() {
+	u.pending = true
+}
+
+// flush sends a snapshot of the game if it changed since the last update
+func (u *updater) flush() {
+	if !u.pending || u.subscriber == nil {
+		return
+	}
+	u.pending = false
+	u.subscriber.OnUpdate(u.game.Snapshot())
+}
+
+// follow handles every line received until lines is closed or ctx is
+// cancelled, and flushes the updater once per frame in between
+func follow[T any](ctx context.Context, lines <-chan T, updates *updater, handle func(T)) {
+	ticker := time.NewTicker(frameInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				updates.flush()
+				return
+			}
+			handle(line)
+		case <-ctx.Done():
+			updates.flush()
+			return
+		case <-ticker.C:
+			updates.flush()
+		}
+	}
+}
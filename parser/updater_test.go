@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// countingSubscriber counts the updates
+type countingSubscriber struct {
+	updates int
+}
+
+func (s *countingSubscriber) OnUpdate(snapshot *models.Snapshot) {
+	s.updates++
+}
+
+func newSampleGame() *models.Game {
+	return models.NewGame(&config.Config{IgnoredPlayers: []string{"<world>"}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestChangesState(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{"2024-04-19 16:02:15 ]\b \bKill: 0 1 10: PlayerOne killed PlayerTwo by MOD_RAILGUN", true},
+		{"2024-04-19 16:02:15 Item: 2 weapon_railgun", true},
+		{"2024-04-19 16:08:00 red:1  blue:0", true},
+		{"2024-04-19 16:02:15 broadcast: print \"PlayerOne connected\\n\"", false},
+		{"2024-04-19 16:01:17 32 bots parsed", false},
+		{"2024-04-19", false},
+	}
+	for _, tt := range tests {
+		if changed := changesState(tt.line); changed != tt.expected {
+			t.Errorf("changesState(%q) = %v, expected %v", tt.line, changed, tt.expected)
+		}
+	}
+}
+
+func TestTailReader_CoalescesUpdates(t *testing.T) {
+	data, err := os.ReadFile("../samples/f24.txt")
+	if err != nil {
+		t.Fatalf("Failed to read sample: %v", err)
+	}
+
+	game := newSampleGame()
+	recorder := &Recorder{}
+	if err := TailReader(context.Background(), bytes.NewReader(data), recorder, game, game.Logger); err != nil {
+		t.Fatalf("TailReader failed: %v", err)
+	}
+
+	updates := len(recorder.Snapshots())
+	if updates == 0 || updates >= game.LinesProcessed/10 {
+		t.Errorf("Expected a few updates for %d lines, got %d", game.LinesProcessed, updates)
+	}
+
+	// The last update is sent when the lines end
+	last := recorder.Last()
+	if len(last.Rounds) != len(game.Rounds) || last.LinesProcessed != game.LinesProcessed {
+		t.Errorf("Expected the last update to have the final state, got %d rounds and %d lines",
+			len(last.Rounds), last.LinesProcessed)
+	}
+}
+
+func TestTailReader_UpdatesAfterEveryRound(t *testing.T) {
+	data, err := os.ReadFile("../samples/f24.txt")
+	if err != nil {
+		t.Fatalf("Failed to read sample: %v", err)
+	}
+
+	game := newSampleGame()
+	recorder := &Recorder{}
+	if err := TailReader(context.Background(), bytes.NewReader(data), recorder, game, game.Logger); err != nil {
+		t.Fatalf("TailReader failed: %v", err)
+	}
+
+	rounds := 0
+	for _, snapshot := range recorder.Snapshots() {
+		if len(snapshot.Rounds) > rounds+1 {
+			t.Fatalf("Expected an update for every round, got %d rounds after %d", len(snapshot.Rounds), rounds)
+		}
+		rounds = len(snapshot.Rounds)
+	}
+	if rounds < 2 {
+		t.Errorf("Expected the sample to have several rounds, got %d", rounds)
+	}
+}
+
+func TestTailLines_NoUpdateWithoutChanges(t *testing.T) {
+	lines := make(chan string, 2)
+	lines <- "2024-04-19 16:01:17 32 bots parsed"
+	lines <- "2024-04-19 16:01:17 ------------------------------------------------------------"
+	close(lines)
+
+	game := newSampleGame()
+	recorder := &Recorder{}
+	if err := TailLines(context.Background(), lines, recorder, game, game.Logger); err != nil {
+		t.Fatalf("TailLines failed: %v", err)
+	}
+	if updates := len(recorder.Snapshots()); updates != 0 {
+		t.Errorf("Expected no updates for lines that change nothing, got %d", updates)
+	}
+}
+
+// BenchmarkCatchUp measures catching up on the sample log, with coalesced
+// updates and with an update for every line
+func BenchmarkCatchUp(b *testing.B) {
+	data, err := os.ReadFile("../samples/f24.txt")
+	if err != nil {
+		b.Fatalf("Failed to read sample: %v", err)
+	}
+
+	b.Run("coalesced", func(b *testing.B) {
+		subscriber := &countingSubscriber{}
+		for i := 0; i < b.N; i++ {
+			game := newSampleGame()
+			if err := TailReader(context.Background(), bytes.NewReader(data), subscriber, game, game.Logger); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(subscriber.updates)/float64(b.N), "updates/op")
+	})
+
+	b.Run("every_line", func(b *testing.B) {
+		subscriber := &countingSubscriber{}
+		for i := 0; i < b.N; i++ {
+			game := newSampleGame()
+			scanner := bufio.NewScanner(bytes.NewReader(data))
+			receivingScores := false
+			for scanner.Scan() {
+				_, receivingScores = ParseLine(scanner.Text(), game, game.Logger, receivingScores)
+				subscriber.OnUpdate(game.Snapshot())
+			}
+		}
+		b.ReportMetric(float64(subscriber.updates)/float64(b.N), "updates/op")
+	})
+}
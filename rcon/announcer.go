@@ -0,0 +1,115 @@
+package rcon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// announcementBuffer is the number of announcements waiting to be said
+// before new ones are dropped
+const announcementBuffer = 64
+
+// Announcer says round results, what each player has to drink, kill
+// streaks and the results of judge commands in the game chat
+// Announcements are said in the background by Run, so a slow or unreachable
+// server never holds up the parser
+type Announcer struct {
+	client     *Client
+	logger     *slog.Logger
+	killStreak int
+
+	announcements chan string
+
+	// Game progress already announced
+	rounds  int
+	events  int
+	streaks map[string]int
+}
+
+// NewAnnouncer creates an announcer that announces every killStreak kills
+// in a row (kill streaks are not announced when killStreak is 0)
+func NewAnnouncer(client *Client, killStreak int, logger *slog.Logger) *Announcer {
+	return &Announcer{
+		client:        client,
+		logger:        logger.With("component", "rcon"),
+		killStreak:    killStreak,
+		announcements: make(chan string, announcementBuffer),
+		streaks:       make(map[string]int),
+	}
+}
+
+// OnUpdate implements parser.Subscriber
+func (a *Announcer) OnUpdate(game *models.Snapshot) {
+	// Lines read while catching up on an old log are not announced
+	if !models.IsLive(game.CurrentTime) {
+		a.rounds = len(game.Rounds)
+		a.events = len(game.Events)
+		return
+	}
+
+	for _, event := range game.Events[a.events:] {
+		if event.Type == models.EventCommand {
+			a.announce(fmt.Sprintf("^3%s", event.Message))
+		}
+	}
+	a.events = len(game.Events)
+
+	for _, round := range game.Rounds[a.rounds:] {
+		if round.IsIgnored() {
+			a.announce(fmt.Sprintf("^3Round ignored: %s", round.IgnoredReason))
+			continue
+		}
+		if len(round.Results) > 0 {
+			winner := round.Results[0]
+			a.announce(fmt.Sprintf("^3Round won by ^7%s^3 with %d kills", winner.Name, winner.Kills))
+		}
+		for _, p := range game.Players {
+			if round.GetResult(p.Name) != nil && p.Diff14 != "" {
+				a.announce(fmt.Sprintf("^7%s^3 drinks %s", p.Name, p.Diff14))
+			}
+		}
+	}
+	a.rounds = len(game.Rounds)
+
+	if a.killStreak <= 0 || game.IsWarmup {
+		return
+	}
+	for _, p := range game.Players {
+		streak := p.RoundCurrentKillingStreak
+		if streak < a.streaks[p.Name] {
+			a.streaks[p.Name] = 0
+		}
+
+		// Updates are coalesced, so the streak may have passed the milestone
+		milestone := streak / a.killStreak * a.killStreak
+		if milestone > 0 && milestone > a.streaks[p.Name] {
+			a.streaks[p.Name] = milestone
+			a.announce(fmt.Sprintf("^1%s^7 is on a %d kill streak!", p.Name, milestone))
+		}
+	}
+}
+
+func (a *Announcer) announce(message string) {
+	select {
+	case a.announcements <- message:
+	default:
+		a.logger.Warn("Dropping announcement, too many pending", "message", message)
+	}
+}
+
+// Run says the announcements until ctx is cancelled
+func (a *Announcer) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-a.announcements:
+			if err := a.client.Say(message); err != nil {
+				a.logger.Warn("Failed to say announcement", "message", message, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package rcon
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// startRound returns a game with a round started at the timestamp
+func startRound(timestamp string) *models.Game {
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game.NewMap("q3dm1", timestamp)
+	game.NewMap("q3dm17", timestamp)
+	game.CurrentTime = timestamp
+	return game
+}
+
+func send(announcer *Announcer, game *models.Game) {
+	announcer.OnUpdate(game.Snapshot())
+}
+
+func TestAnnouncer_AnnouncesRoundAndStreaks(t *testing.T) {
+	server := newServerStandIn(t, "Hunter2")
+	announcer := NewAnnouncer(NewClient(server.address(), "Hunter2"), 5, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go announcer.Run(ctx)
+
+	game := startRound(time.Now().Format(models.TimestampLayout))
+	for i := 0; i < 10; i++ {
+		game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+		send(announcer, game)
+	}
+	game.RecordKill("PlayerTwo", "PlayerOne", "MOD_RAILGUN")
+	game.Save()
+	send(announcer, game)
+	send(announcer, game)
+
+	commands := server.waitForCommands(t, 5)
+	time.Sleep(20 * time.Millisecond)
+	expected := []string{
+		"say ^1PlayerOne^7 is on a 5 kill streak!",
+		"say ^1PlayerOne^7 is on a 10 kill streak!",
+		"say ^3Round won by ^7PlayerOne^3 with 10 kills",
+		"say ^7PlayerOne^3 drinks 1 beer",
+		"say ^7PlayerTwo^3 drinks 1 sip",
+	}
+	commands = server.received()
+	if len(commands) != len(expected) {
+		t.Fatalf("Expected %d commands, got %q", len(expected), commands)
+	}
+	for i := range expected {
+		if commands[i] != expected[i] {
+			t.Errorf("Command %d: expected %q, got %q", i, expected[i], commands[i])
+		}
+	}
+}
+
+func TestAnnouncer_AnnouncesIgnoredRound(t *testing.T) {
+	server := newServerStandIn(t, "Hunter2")
+	announcer := NewAnnouncer(NewClient(server.address(), "Hunter2"), 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go announcer.Run(ctx)
+
+	game := startRound(time.Now().Format(models.TimestampLayout))
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.EndRound(false)
+	send(announcer, game)
+
+	server.waitForCommands(t, 1)
+	time.Sleep(20 * time.Millisecond)
+	commands := server.received()
+	expected := "say ^3Round ignored: ended by a map change rather than Exit"
+	if len(commands) != 1 || commands[0] != expected {
+		t.Errorf("Expected only %q, got %q", expected, commands)
+	}
+}
+
+func TestAnnouncer_SkipsOldLogLines(t *testing.T) {
+	announcer := NewAnnouncer(NewClient("127.0.0.1:0", "Hunter2"), 5, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Catching up on an old log
+	game := startRound("2024-04-19 16:10:12")
+	for i := 0; i < 5; i++ {
+		game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	}
+	game.Save()
+	send(announcer, game)
+
+	if len(announcer.announcements) != 0 {
+		t.Errorf("Expected no announcements for old log lines, got %d", len(announcer.announcements))
+	}
+
+	// Back to live, the old round is not announced anymore
+	game.CurrentTime = time.Now().Format(models.TimestampLayout)
+	send(announcer, game)
+
+	if len(announcer.announcements) != 0 {
+		t.Errorf("Expected old round not to be announced, got %d announcements", len(announcer.announcements))
+	}
+}
+
+func TestAnnouncer_AnnouncesStreakPassedBetweenUpdates(t *testing.T) {
+	announcer := NewAnnouncer(NewClient("127.0.0.1:0", "Hunter2"), 5, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	game := startRound(time.Now().Format(models.TimestampLayout))
+	for i := 0; i < 6; i++ {
+		game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	}
+	send(announcer, game)
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	send(announcer, game)
+
+	if len(announcer.announcements) != 1 {
+		t.Fatalf("Expected 1 announcement, got %d", len(announcer.announcements))
+	}
+	if message := <-announcer.announcements; message != "^1PlayerOne^7 is on a 5 kill streak!" {
+		t.Errorf("Expected the 5 kill streak to be announced, got %q", message)
+	}
+}
+
+func TestAnnouncer_StreakEndsWithTheRound(t *testing.T) {
+	announcer := NewAnnouncer(NewClient("127.0.0.1:0", "Hunter2"), 5, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	game := startRound(time.Now().Format(models.TimestampLayout))
+	for i := 0; i < 4; i++ {
+		game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	}
+	game.Save()
+	send(announcer, game)
+	for len(announcer.announcements) > 0 {
+		<-announcer.announcements
+	}
+
+	// A kill in the next round starts a new streak
+	game.NewMap("q3dm6", game.CurrentTime)
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	send(announcer, game)
+
+	if len(announcer.announcements) != 0 {
+		t.Errorf("Expected no streak across rounds, got %q", <-announcer.announcements)
+	}
+	if streak := game.Players["PlayerOne"].RoundCurrentKillingStreak; streak != 1 {
+		t.Errorf("Expected a streak of 1 in the new round, got %d", streak)
+	}
+}
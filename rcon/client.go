@@ -0,0 +1,110 @@
+package rcon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// packetPrefix starts every out-of-band Quake 3 packet
+	packetPrefix = "\xff\xff\xff\xff"
+
+	// responseHeader starts the server response to an rcon command
+	responseHeader = "print\n"
+
+	defaultTimeout = 2 * time.Second
+
+	// followUpTimeout is how long to wait for more packets of a long response
+	followUpTimeout = 100 * time.Millisecond
+
+	maxPacketSize = 16384
+)
+
+// ErrBadPassword is returned when the server rejects the rcon password
+var ErrBadPassword = errors.New("bad rcon password")
+
+// Client sends rcon commands to a Quake 3 server over UDP
+type Client struct {
+	address  string
+	password string
+	timeout  time.Duration
+}
+
+// NewClient creates a client for the server at address, e.g. "127.0.0.1:27960"
+func NewClient(address string, password string) *Client {
+	return &Client{
+		address:  address,
+		password: password,
+		timeout:  defaultTimeout,
+	}
+}
+
+// Command runs an rcon command and returns the server output
+func (c *Client) Command(command string) (string, error) {
+	conn, err := net.Dial("udp", c.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", c.address, err)
+	}
+	defer conn.Close()
+
+	packet := fmt.Sprintf("%srcon %s %s", packetPrefix, c.password, command)
+	if _, err := conn.Write([]byte(packet)); err != nil {
+		return "", fmt.Errorf("failed to send rcon command: %w", err)
+	}
+
+	response, err := c.readResponse(conn)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(response, "Bad rconpassword.") {
+		return "", ErrBadPassword
+	}
+	return response, nil
+}
+
+// Say prints a chat message to all players
+func (c *Client) Say(message string) error {
+	_, err := c.Command("say " + sanitize(message))
+	return err
+}
+
+// readResponse reads the response, which the server splits over several
+// packets when it is long
+func (c *Client) readResponse(conn net.Conn) (string, error) {
+	var response strings.Builder
+	buf := make([]byte, maxPacketSize)
+
+	deadline := time.Now().Add(c.timeout)
+	for {
+		conn.SetReadDeadline(deadline)
+		n, err := conn.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && response.Len() > 0 {
+				return response.String(), nil
+			}
+			return "", fmt.Errorf("failed to read rcon response: %w", err)
+		}
+
+		packet, ok := bytes.CutPrefix(buf[:n], []byte(packetPrefix+responseHeader))
+		if !ok {
+			return "", fmt.Errorf("unexpected rcon response: %q", buf[:n])
+		}
+		response.Write(packet)
+
+		// An empty response still counts as an answer
+		if response.Len() == 0 {
+			return "", nil
+		}
+		deadline = time.Now().Add(followUpTimeout)
+	}
+}
+
+// sanitize removes characters that would break the command or the chat line
+func sanitize(message string) string {
+	return strings.NewReplacer(`"`, "'", ";", ",", "\n", " ").Replace(message)
+}
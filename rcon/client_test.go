@@ -0,0 +1,144 @@
+package rcon
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// serverStandIn is a local UDP server answering rcon commands like ioq3ded
+type serverStandIn struct {
+	conn     net.PacketConn
+	password string
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func newServerStandIn(t *testing.T, password string) *serverStandIn {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	s := &serverStandIn{conn: conn, password: password}
+	go s.serve()
+	return s
+}
+
+func (s *serverStandIn) address() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *serverStandIn) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		request, ok := strings.CutPrefix(string(buf[:n]), packetPrefix+"rcon ")
+		if !ok {
+			continue
+		}
+		password, command, _ := strings.Cut(request, " ")
+		if password != s.password {
+			s.conn.WriteTo([]byte(packetPrefix+"print\nBad rconpassword.\n"), addr)
+			continue
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		switch {
+		case strings.HasPrefix(command, "say "):
+			s.conn.WriteTo([]byte(packetPrefix+"print\n"), addr)
+		case command == "status":
+			// Long responses are split over several packets
+			s.conn.WriteTo([]byte(packetPrefix+"print\nmap: q3dm17\n"), addr)
+			s.conn.WriteTo([]byte(packetPrefix+"print\nnum score ping name\n"), addr)
+		}
+	}
+}
+
+func (s *serverStandIn) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+// waitForCommands waits until the stand-in has received count commands
+func (s *serverStandIn) waitForCommands(t *testing.T, count int) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if commands := s.received(); len(commands) >= count {
+			return commands
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d commands, got %v", count, s.received())
+	return nil
+}
+
+func TestClient_Say(t *testing.T) {
+	server := newServerStandIn(t, "Hunter2")
+	client := NewClient(server.address(), "Hunter2")
+
+	if err := client.Say(`PlayerOne "won"; quit`); err != nil {
+		t.Fatalf("Say failed: %v", err)
+	}
+
+	commands := server.received()
+	if len(commands) != 1 || commands[0] != "say PlayerOne 'won', quit" {
+		t.Errorf("Unexpected commands: %q", commands)
+	}
+}
+
+func TestClient_MultiPacketResponse(t *testing.T) {
+	server := newServerStandIn(t, "Hunter2")
+	client := NewClient(server.address(), "Hunter2")
+
+	response, err := client.Command("status")
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if response != "map: q3dm17\nnum score ping name\n" {
+		t.Errorf("Unexpected response: %q", response)
+	}
+}
+
+func TestClient_BadPassword(t *testing.T) {
+	server := newServerStandIn(t, "Hunter2")
+	client := NewClient(server.address(), "wrong")
+
+	if err := client.Say("hello"); !errors.Is(err, ErrBadPassword) {
+		t.Errorf("Expected ErrBadPassword, got %v", err)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	// Nothing answers on this socket
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := NewClient(conn.LocalAddr().String(), "Hunter2")
+	client.timeout = 50 * time.Millisecond
+
+	if err := client.Say("hello"); err == nil {
+		t.Error("Expected timeout error, got nil")
+	}
+}
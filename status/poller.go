@@ -0,0 +1,65 @@
+package status
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// queryTimeout is how long to wait for the server to answer a poll
+const queryTimeout = 2 * time.Second
+
+// Subscriber receives every polled server status
+type Subscriber interface {
+	OnServerStatus(status *models.ServerStatus)
+}
+
+// Poller periodically queries the server status and passes it on
+type Poller struct {
+	address    string
+	interval   time.Duration
+	subscriber Subscriber
+	logger     *slog.Logger
+}
+
+// NewPoller creates a poller for the server at address
+// Every status is passed on to the subscriber
+func NewPoller(address string, interval time.Duration, subscriber Subscriber, logger *slog.Logger) *Poller {
+	return &Poller{
+		address:    address,
+		interval:   interval,
+		subscriber: subscriber,
+		logger:     logger.With("component", "status"),
+	}
+}
+
+// Run polls the server until ctx is cancelled
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.poll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) poll() {
+	status, err := Query(p.address, queryTimeout)
+	if err != nil {
+		p.logger.Warn("Failed to poll server", "error", err)
+		return
+	}
+
+	p.logger.Debug("Server status", "hostname", status.Hostname, "map", status.MapName, "players", len(status.Players))
+	if p.subscriber != nil {
+		p.subscriber.OnServerStatus(status)
+	}
+}
@@ -0,0 +1,117 @@
+package status
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+const (
+	// packetPrefix starts every out-of-band Quake 3 packet
+	packetPrefix = "\xff\xff\xff\xff"
+
+	getStatusRequest = packetPrefix + "getstatus"
+	responseHeader   = packetPrefix + "statusResponse\n"
+
+	maxPacketSize = 16384
+)
+
+// colorCode matches Quake 3 color codes such as ^7
+var colorCode = regexp.MustCompile(`\^[0-9a-zA-Z]`)
+
+// Query asks the server at address for its status
+func Query(address string, timeout time.Duration) (*models.ServerStatus, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(getStatusRequest)); err != nil {
+		return nil, fmt.Errorf("failed to send getstatus: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, maxPacketSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response: %w", err)
+	}
+
+	return parseStatusResponse(string(buf[:n]))
+}
+
+// parseStatusResponse parses a getstatus response:
+//
+//	\xff\xff\xff\xffstatusResponse
+//	\key\value\key\value...
+//	score ping "name"
+//	...
+func parseStatusResponse(response string) (*models.ServerStatus, error) {
+	body, ok := strings.CutPrefix(response, responseHeader)
+	if !ok {
+		return nil, fmt.Errorf("unexpected status response: %q", response)
+	}
+
+	lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+	info := parseInfoString(lines[0])
+	status := &models.ServerStatus{
+		Hostname:  stripColors(info["sv_hostname"]),
+		MapName:   info["mapname"],
+		Info:      info,
+		Players:   make([]models.ConnectedPlayer, 0, len(lines)-1),
+		UpdatedAt: time.Now(),
+	}
+
+	for _, line := range lines[1:] {
+		player, err := parsePlayerLine(line)
+		if err != nil {
+			return nil, err
+		}
+		status.Players = append(status.Players, player)
+	}
+
+	return status, nil
+}
+
+// parseInfoString parses a \key\value\key\value info string
+func parseInfoString(infoString string) map[string]string {
+	info := make(map[string]string)
+	parts := strings.Split(strings.TrimPrefix(infoString, `\`), `\`)
+	for i := 0; i+1 < len(parts); i += 2 {
+		info[parts[i]] = parts[i+1]
+	}
+	return info
+}
+
+// parsePlayerLine parses a `score ping "name"` line
+func parsePlayerLine(line string) (models.ConnectedPlayer, error) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) != 3 {
+		return models.ConnectedPlayer{}, fmt.Errorf("invalid player line in status response: %q", line)
+	}
+
+	score, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return models.ConnectedPlayer{}, fmt.Errorf("invalid score in status response: %q", line)
+	}
+	ping, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return models.ConnectedPlayer{}, fmt.Errorf("invalid ping in status response: %q", line)
+	}
+
+	return models.ConnectedPlayer{
+		Name:  stripColors(strings.Trim(fields[2], `"`)),
+		Score: score,
+		Ping:  ping,
+	}, nil
+}
+
+func stripColors(s string) string {
+	return colorCode.ReplaceAllString(s, "")
+}
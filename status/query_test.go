@@ -0,0 +1,151 @@
+package status
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+const sampleResponse = "\xff\xff\xff\xffstatusResponse\n" +
+	`\sv_hostname\^1Death^7quake\mapname\q3dm17\g_gametype\0\sv_maxclients\16` + "\n" +
+	`20 12 "PlayerOne"` + "\n" +
+	`-1 0 "^2Multi Word^7 Name"` + "\n"
+
+// newFakeServer starts a local UDP server answering getstatus with response
+func newFakeServer(t *testing.T, response string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if string(buf[:n]) == getStatusRequest {
+				conn.WriteTo([]byte(response), addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestQuery(t *testing.T) {
+	address := newFakeServer(t, sampleResponse)
+
+	status, err := Query(address, time.Second)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if status.Hostname != "Deathquake" {
+		t.Errorf("Expected hostname without colors, got %q", status.Hostname)
+	}
+	if status.MapName != "q3dm17" {
+		t.Errorf("Expected map q3dm17, got %q", status.MapName)
+	}
+	if status.Info["sv_maxclients"] != "16" {
+		t.Errorf("Expected info string to be parsed, got %v", status.Info)
+	}
+
+	expected := []models.ConnectedPlayer{
+		{Name: "PlayerOne", Score: 20, Ping: 12},
+		{Name: "Multi Word Name", Score: -1, Ping: 0},
+	}
+	if len(status.Players) != len(expected) {
+		t.Fatalf("Expected %d players, got %d", len(expected), len(status.Players))
+	}
+	for i := range expected {
+		if status.Players[i] != expected[i] {
+			t.Errorf("Player %d: expected %+v, got %+v", i, expected[i], status.Players[i])
+		}
+	}
+}
+
+func TestQuery_Timeout(t *testing.T) {
+	// Nothing answers on this socket
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := Query(conn.LocalAddr().String(), 50*time.Millisecond); err == nil {
+		t.Error("Expected timeout error, got nil")
+	}
+}
+
+func TestParseStatusResponse_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{name: "Wrong header", response: "\xff\xff\xff\xffinfoResponse\n\\mapname\\q3dm17\n"},
+		{name: "Invalid player line", response: "\xff\xff\xff\xffstatusResponse\n\\mapname\\q3dm17\nnot a player\n"},
+		{name: "Invalid ping", response: "\xff\xff\xff\xffstatusResponse\n\\mapname\\q3dm17\n1 x \"Name\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseStatusResponse(tt.response); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+// recordingSubscriber records the statuses passed to it
+type recordingSubscriber struct {
+	mu       sync.Mutex
+	statuses []*models.ServerStatus
+}
+
+func (s *recordingSubscriber) OnServerStatus(status *models.ServerStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses = append(s.statuses, status)
+}
+
+func (s *recordingSubscriber) received() []*models.ServerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*models.ServerStatus(nil), s.statuses...)
+}
+
+func TestPoller_FeedsSubscriber(t *testing.T) {
+	address := newFakeServer(t, sampleResponse)
+	subscriber := &recordingSubscriber{}
+
+	poller := NewPoller(address, time.Hour, subscriber, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go poller.Run(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(subscriber.received()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	statuses := subscriber.received()
+	if len(statuses) != 1 {
+		t.Fatalf("Expected 1 status, got %d", len(statuses))
+	}
+	if status := statuses[0]; status.MapName != "q3dm17" || len(status.Players) != 2 {
+		t.Errorf("Unexpected server status: %+v", status)
+	}
+}
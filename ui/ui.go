@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -25,10 +26,23 @@ const (
 	columnKeyGauntlet       = "gauntlet"
 	columnKeySuicide        = "suicide"
 	columnKeyKillStreak     = "kill_streak"
-
-	winningScore = 16
+	columnKeyRating         = "rating"
+	columnKeyTeam           = "team"
+	columnKeyCaptures       = "captures"
+	columnKeyTimePlayed     = "time_played"
+	columnKeyKillsPerMinute = "kills_per_minute"
+	columnKeyOwed           = "owed"
 )
 
+// chatLines is the number of chat messages shown under the table
+const chatLines = 5
+
+// parseErrorLines is the number of unparsed lines shown in the parse errors panel
+const parseErrorLines = 10
+
+// parseErrorsKey toggles the parse errors panel
+const parseErrorsKey = "e"
+
 var (
 	black       = lipgloss.Color("0")
 	white       = lipgloss.Color("15")
@@ -37,29 +51,63 @@ var (
 	gameWinner  = lipgloss.NewStyle().Background(red).Foreground(white).Bold(true)
 	roundWinner = lipgloss.NewStyle().Background(blue).Foreground(white).Bold(true)
 	highlight   = lipgloss.NewStyle().Background(black).Foreground(white)
+	warning     = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
+	redTeam     = lipgloss.NewStyle().Foreground(red).Bold(true)
+	blueTeam    = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true)
 	normal      = lipgloss.NewStyle()
 )
 
 type Model struct {
-	title string
-	table table.Model
+	title      string
+	teamScores string
+	awards     string
+	chat       string
+	status     *models.ServerStatus
+	table      table.Model
+
+	// Parser health
+	parseErrors     string
+	parseErrorLog   []models.ParseError
+	showParseErrors bool
 }
 
+// GameUpdate carries a snapshot of the game after a log line was parsed
 type GameUpdate struct {
 	Players []*models.Player
-	Game    *models.Game
+	Game    *models.Snapshot
+}
+
+// ProgramSubscriber forwards the game updates and the server status to the
+// terminal UI running in the program
+type ProgramSubscriber struct {
+	program *tea.Program
+}
+
+func NewProgramSubscriber(program *tea.Program) *ProgramSubscriber {
+	return &ProgramSubscriber{program: program}
+}
+
+// OnUpdate implements parser.Subscriber
+func (s *ProgramSubscriber) OnUpdate(snapshot *models.Snapshot) {
+	s.program.Send(GameUpdate{Players: snapshot.Players, Game: snapshot})
+}
+
+// OnServerStatus implements status.Subscriber
+func (s *ProgramSubscriber) OnServerStatus(status *models.ServerStatus) {
+	s.program.Send(ServerStatusUpdate{Status: status})
+}
+
+// ServerStatusUpdate carries the latest status polled from the server
+type ServerStatusUpdate struct {
+	Status *models.ServerStatus
 }
 
 func NewModel() Model {
 	return Model{
-		table: table.New(generateColumns()),
+		table: table.New(generateColumns(models.GameTypeFFA)),
 	}
 }
 
-func CreateGameUpdate(update GameUpdate) tea.Msg {
-	return update
-}
-
 const float64EqualityThreshold = 1e-5
 
 func almostEqual(a, b float64) bool {
@@ -96,13 +144,121 @@ func rankToString(rank int, prevRank int) string {
 	}
 }
 
-func generateColumns() []table.Column {
-	return []table.Column{
+func ratingToString(rating float64, ratingDiff float64) string {
+	if math.Round(ratingDiff) > 0 {
+		return fmt.Sprintf("%.0f ▲%.0f", rating, ratingDiff)
+	}
+	if math.Round(ratingDiff) < 0 {
+		return fmt.Sprintf("%.0f ▼%.0f", rating, -ratingDiff)
+	}
+	return fmt.Sprintf("%.0f", rating)
+}
+
+// statusToString formats the server status for the header, e.g.
+// "q3dm17 · 2 connected: PlayerOne (12 ms), PlayerTwo (30 ms)"
+func statusToString(status *models.ServerStatus) string {
+	if status == nil {
+		return ""
+	}
+
+	players := make([]string, 0, len(status.Players))
+	for _, p := range status.Players {
+		players = append(players, fmt.Sprintf("%s (%d ms)", p.Name, p.Ping))
+	}
+
+	result := fmt.Sprintf("%s · %d connected", status.MapName, len(status.Players))
+	if len(players) > 0 {
+		result += ": " + strings.Join(players, ", ")
+	}
+	return result
+}
+
+// timePlayedToString formats the time played in hours and minutes, e.g. "2:47"
+func timePlayedToString(d time.Duration) string {
+	minutes := int(d.Minutes())
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+// nameToString marks players that joined the current round late
+func nameToString(player *models.Player) string {
+	if player.RoundJoinedLate {
+		return player.Name + " (late)"
+	}
+	return player.Name
+}
+
+// teamToString renders the team in its color
+func teamToString(team models.Team) string {
+	switch team {
+	case models.TeamRed:
+		return redTeam.Render(team.String())
+	case models.TeamBlue:
+		return blueTeam.Render(team.String())
+	case models.TeamSpectator:
+		return team.String()
+	default:
+		return ""
+	}
+}
+
+// teamScoresToString formats the team scores of the current round, e.g. "Red 3 · Blue 1"
+func teamScoresToString(scores map[models.Team]int) string {
+	return fmt.Sprintf("%s %d · %s %d",
+		redTeam.Render("Red"), scores[models.TeamRed], blueTeam.Render("Blue"), scores[models.TeamBlue])
+}
+
+// awardsToString formats the awards, e.g. "Most Quads: PlayerOne (5) · Map Controller: ..."
+func awardsToString(awards []models.Award) string {
+	parts := make([]string, 0, len(awards))
+	for _, award := range awards {
+		parts = append(parts, award.String())
+	}
+	return strings.Join(parts, " · ")
+}
+
+// chatToString formats the most recent chat messages, one per line
+func chatToString(chatLog []models.ChatMessage) string {
+	if len(chatLog) > chatLines {
+		chatLog = chatLog[len(chatLog)-chatLines:]
+	}
+	lines := make([]string, 0, len(chatLog))
+	for _, msg := range chatLog {
+		name := msg.Player
+		if msg.TeamOnly {
+			name += " (team)"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", lipgloss.NewStyle().Bold(true).Render(name), msg.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseErrorsToString formats the most recent lines that could not be parsed,
+// one per line, e.g. "line 1234 (kill): invalid kill event: ..."
+func parseErrorsToString(parseErrorLog []models.ParseError) string {
+	if len(parseErrorLog) > parseErrorLines {
+		parseErrorLog = parseErrorLog[len(parseErrorLog)-parseErrorLines:]
+	}
+	lines := make([]string, 0, len(parseErrorLog))
+	for _, parseErr := range parseErrorLog {
+		lines = append(lines, fmt.Sprintf("line %d (%s): %s", parseErr.LineNumber, parseErr.Category, parseErr.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// generateColumns returns the table columns, with team columns in team games
+func generateColumns(gameType int) []table.Column {
+	columns := []table.Column{
 		table.NewColumn(columnKeyRank, "Rank", 8),
 		table.NewColumn(columnKeyName, "Name", 20),
+	}
+	if models.IsTeamGameType(gameType) {
+		columns = append(columns, table.NewColumn(columnKeyTeam, "Team", 8))
+	}
+	columns = append(columns,
 		table.NewColumn(columnKeyScore, "Score", 10),
 		table.NewColumn(columnKeyScore14, "Score 14", 20),
 		table.NewColumn(columnKeyDiff14, "Diff 14", 12),
+		table.NewColumn(columnKeyOwed, "Owed", 20),
 		table.NewColumn(columnKeyKills, "Kills", 8),
 		table.NewColumn(columnKeyDeaths, "Deaths", 8),
 		table.NewColumn(columnKeyKillDeathRatio, "Ratio", 8),
@@ -111,7 +267,14 @@ func generateColumns() []table.Column {
 		table.NewColumn(columnKeyGauntlet, "Gauntlet Kills", 8),
 		table.NewColumn(columnKeyKillStreak, "Streak", 8),
 		table.NewColumn(columnKeySuicide, "Suicide Deaths", 8),
+		table.NewColumn(columnKeyRating, "Rating", 12),
+		table.NewColumn(columnKeyTimePlayed, "Played", 8),
+		table.NewColumn(columnKeyKillsPerMinute, "Kills/min", 10),
+	)
+	if gameType == models.GameTypeCTF {
+		columns = append(columns, table.NewColumn(columnKeyCaptures, "Captures", 10))
 	}
+	return columns
 }
 
 func (m Model) Init() tea.Cmd {
@@ -132,11 +295,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "ctrl+c", "esc", "q":
 			cmds = append(cmds, tea.Quit)
+		case parseErrorsKey:
+			m.showParseErrors = !m.showParseErrors
 		}
 
 	case GameUpdate:
 		m.title = fmt.Sprintf("%s Deathquake", "💀")
-		m.table = m.table.WithRows(generateRowsFromData(msg)).WithColumns(generateColumns())
+		m.table = m.table.WithRows(generateRowsFromData(msg)).WithColumns(generateColumns(msg.Game.GameType))
+		m.teamScores = ""
+		if msg.Game.IsTeamGame() {
+			m.teamScores = teamScoresToString(msg.Game.TeamScores)
+		}
+		m.awards = awardsToString(msg.Game.Awards)
+		m.chat = chatToString(msg.Game.ChatLog)
+		m.parseErrors = models.ParseErrorSummary(msg.Game.ParseErrorCounts)
+		m.parseErrorLog = msg.Game.ParseErrorLog
+
+	case ServerStatusUpdate:
+		m.status = msg.Status
 	}
 
 	return m, tea.Batch(cmds...)
@@ -146,8 +322,31 @@ func (m Model) View() string {
 	body := strings.Builder{}
 	title := lipgloss.NewStyle().MarginLeft(2).Bold(true).MarginTop(1)
 	body.WriteString(title.Render(m.title))
+	if m.teamScores != "" {
+		body.WriteString("  " + m.teamScores)
+	}
+	if m.status != nil {
+		status := lipgloss.NewStyle().MarginLeft(2)
+		body.WriteString("\n" + status.Render(statusToString(m.status)))
+	}
+	if m.parseErrors != "" {
+		health := lipgloss.NewStyle().MarginLeft(2)
+		body.WriteString("\n" + health.Render(warning.Render("⚠ "+m.parseErrors)+fmt.Sprintf(" (press %s to view)", parseErrorsKey)))
+	}
 	pad := lipgloss.NewStyle().Margin(1)
 	body.WriteString(pad.Render(m.table.View()))
+	if m.awards != "" {
+		awards := lipgloss.NewStyle().MarginLeft(2)
+		body.WriteString("\n" + awards.Render(m.awards))
+	}
+	if m.chat != "" {
+		chat := lipgloss.NewStyle().MarginLeft(2).MarginTop(1)
+		body.WriteString("\n" + chat.Render(m.chat))
+	}
+	if m.showParseErrors && len(m.parseErrorLog) > 0 {
+		parseErrors := lipgloss.NewStyle().MarginLeft(2).MarginTop(1)
+		body.WriteString("\n" + parseErrors.Render(parseErrorsToString(m.parseErrorLog)))
+	}
 	return body.String()
 }
 
@@ -157,10 +356,11 @@ func generateRowsFromData(update GameUpdate) []table.Row {
 	for _, player := range update.Players {
 		row := table.NewRow(table.RowData{
 			columnKeyRank:           rankToString(player.Rank, player.PrevRank),
-			columnKeyName:           player.Name,
+			columnKeyName:           nameToString(player),
 			columnKeyScore:          fmt.Sprintf("%.4f", player.Score),
 			columnKeyScore14:        player.Score14,
 			columnKeyDiff14:         normal.Render(player.Diff14),
+			columnKeyOwed:           player.Owed14(),
 			columnKeyKills:          formatIntStat(player.Kills, update.Game.MaxKills, true),
 			columnKeyDeaths:         formatIntStat(player.Deaths, update.Game.MaxDeaths, true),
 			columnKeyKillDeathRatio: formatFloatStat(player.KillDeathRatio, update.Game.MaxKillDeathRatio, true),
@@ -169,13 +369,18 @@ func generateRowsFromData(update GameUpdate) []table.Row {
 			columnKeyGauntlet:       formatIntStat(player.GauntletKills, update.Game.MaxGauntletKills, true),
 			columnKeySuicide:        formatIntStat(player.SuicideDeaths, update.Game.MaxSuicides, true),
 			columnKeyKillStreak:     formatIntStat(player.KillingStreak, update.Game.MaxKillingStreak, true),
+			columnKeyRating:         ratingToString(player.Rating, player.RatingDiff),
+			columnKeyTeam:           teamToString(player.Team),
+			columnKeyCaptures:       fmt.Sprintf("%d", player.Captures),
+			columnKeyTimePlayed:     timePlayedToString(player.TimePlayed),
+			columnKeyKillsPerMinute: fmt.Sprintf("%.2f", player.KillsPerMinute()),
 		})
 
-		if almostEqual(player.Diff, float64(1)) {
+		if player.IsRoundWinner() {
 			row = row.WithStyle(roundWinner)
 		}
 
-		if player.Score > winningScore && player.Rank == 1 {
+		if player.IsGameWinner() {
 			row = row.WithStyle(gameWinner)
 		}
 
@@ -1,8 +1,18 @@
 package ui
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
 	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+	"github.com/fjerlv/deathquake-go/parser"
 )
 
 func TestAlmostEqual(t *testing.T) {
@@ -206,3 +216,133 @@ func TestFormatFloatStatConsistency(t *testing.T) {
 		t.Error("formatFloatStat should produce consistent results for same inputs")
 	}
 }
+
+func TestRatingToString(t *testing.T) {
+	tests := []struct {
+		name       string
+		rating     float64
+		ratingDiff float64
+		expected   string
+	}{
+		{name: "No change", rating: 1500, ratingDiff: 0, expected: "1500"},
+		{name: "Rating went up", rating: 1516, ratingDiff: 16, expected: "1516 ▲16"},
+		{name: "Rating went down", rating: 1484.4, ratingDiff: -15.6, expected: "1484 ▼16"},
+		{name: "Change rounds to zero", rating: 1500.2, ratingDiff: 0.2, expected: "1500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ratingToString(tt.rating, tt.ratingDiff)
+			if result != tt.expected {
+				t.Errorf("ratingToString(%v, %v) = %q, want %q", tt.rating, tt.ratingDiff, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatusToString(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   *models.ServerStatus
+		expected string
+	}{
+		{name: "Not polled", status: nil, expected: ""},
+		{
+			name:     "Empty server",
+			status:   &models.ServerStatus{MapName: "q3dm17"},
+			expected: "q3dm17 · 0 connected",
+		},
+		{
+			name: "Connected players with pings",
+			status: &models.ServerStatus{
+				MapName: "q3dm6",
+				Players: []models.ConnectedPlayer{
+					{Name: "PlayerOne", Ping: 12},
+					{Name: "PlayerTwo", Ping: 30},
+				},
+			},
+			expected: "q3dm6 · 2 connected: PlayerOne (12 ms), PlayerTwo (30 ms)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := statusToString(tt.status); result != tt.expected {
+				t.Errorf("statusToString() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// renderSubscriber renders every update it can keep up with in its own
+// goroutine, like the tea program does
+type renderSubscriber struct {
+	updates chan tea.Msg
+}
+
+func (s *renderSubscriber) OnUpdate(snapshot *models.Snapshot) {
+	select {
+	case s.updates <- GameUpdate{Players: snapshot.Players, Game: snapshot}:
+	default:
+	}
+}
+
+func TestModel_RendersWhileTailing(t *testing.T) {
+	file, err := os.Open("../samples/f24.txt")
+	if err != nil {
+		t.Fatalf("Failed to open sample: %v", err)
+	}
+	defer file.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{IgnoredPlayers: []string{"<world>"}}, logger)
+	subscriber := &renderSubscriber{updates: make(chan tea.Msg, 1)}
+
+	rendered := make(chan int)
+	go func() {
+		var model tea.Model = NewModel()
+		count := 0
+		for msg := range subscriber.updates {
+			model, _ = model.Update(msg)
+			_ = model.View()
+			count++
+		}
+		rendered <- count
+	}()
+
+	if err := parser.TailReader(context.Background(), file, subscriber, game, logger); err != nil {
+		t.Fatalf("TailReader failed: %v", err)
+	}
+	close(subscriber.updates)
+
+	if count := <-rendered; count == 0 {
+		t.Error("Expected some updates to be rendered")
+	}
+	if game.ParseErrors != 0 || len(game.Rounds) == 0 {
+		t.Errorf("Expected the sample to parse into rounds without errors, got %d errors and %d rounds", game.ParseErrors, len(game.Rounds))
+	}
+}
+
+func TestModel_ShowsParseErrors(t *testing.T) {
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	parseErr := models.NewParseError(models.ParseErrorKill, "Kill: broken", errors.New("invalid kill event"))
+	parseErr.LineNumber = 42
+	game.RecordParseError(parseErr)
+
+	var model tea.Model = NewModel()
+	snapshot := game.Snapshot()
+	model, _ = model.Update(GameUpdate{Players: snapshot.Players, Game: snapshot})
+
+	view := model.View()
+	if !strings.Contains(view, "1 kill line could not be parsed") {
+		t.Errorf("Expected the parse health status line, got %q", view)
+	}
+	if strings.Contains(view, "line 42") {
+		t.Errorf("Expected the unparsed lines to be hidden until toggled")
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(parseErrorsKey)})
+	if view := model.View(); !strings.Contains(view, "line 42 (kill): invalid kill event") {
+		t.Errorf("Expected the unparsed lines to be shown, got %q", view)
+	}
+}
@@ -0,0 +1,301 @@
+package web
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strconv"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// APIVersion is the version of the JSON schema served under /api
+// It is bumped on every incompatible change to the schema
+const APIVersion = 1
+
+type apiPlayer struct {
+	Name            string         `json:"name"`
+	Team            string         `json:"team"`
+	Rank            int            `json:"rank"`
+	PrevRank        int            `json:"prev_rank"`
+	Score           float64        `json:"score"`
+	Score14         string         `json:"score14"`
+	Diff            float64        `json:"diff"`
+	Diff14          string         `json:"diff14"`
+	Kills           int            `json:"kills"`
+	Deaths          int            `json:"deaths"`
+	KillDeathRatio  float64        `json:"kill_death_ratio"`
+	RocketKills     int            `json:"rocket_kills"`
+	RailgunKills    int            `json:"railgun_kills"`
+	GauntletKills   int            `json:"gauntlet_kills"`
+	SuicideDeaths   int            `json:"suicide_deaths"`
+	KillingStreak   int            `json:"killing_streak"`
+	Captures        int            `json:"captures"`
+	FlagReturns     int            `json:"flag_returns"`
+	FlagTakes       int            `json:"flag_takes"`
+	RoundKills      int            `json:"round_kills"`
+	RoundDeaths     int            `json:"round_deaths"`
+	Rating          float64        `json:"rating"`
+	RatingDiff      float64        `json:"rating_diff"`
+	IsDrinkingCider bool           `json:"is_drinking_cider"`
+	IsBot           bool           `json:"is_bot"`
+	Drank           float64        `json:"drank"`
+	Owed14          string         `json:"owed14"`
+	Items           map[string]int `json:"items"`
+	TimePlayed      float64        `json:"time_played_seconds"`
+	KillsPerMinute  float64        `json:"kills_per_minute"`
+	RoundJoinedLate bool           `json:"round_joined_late"`
+}
+
+type apiRound struct {
+	Id        string           `json:"id"`
+	LegacyId  string           `json:"legacy_id"`
+	Map       string           `json:"map"`
+	StartedAt string           `json:"started_at"`
+	EndedAt   string           `json:"ended_at"`
+	GameType  int              `json:"game_type"`
+	RedScore  int              `json:"red_score"`
+	BlueScore int              `json:"blue_score"`
+	Results   []apiRoundResult `json:"results"`
+
+	// Why the round does not count, empty for rounds that count
+	IgnoredReason string `json:"ignored_reason"`
+}
+
+type apiRoundResult struct {
+	Name       string  `json:"name"`
+	Team       string  `json:"team"`
+	Kills      int     `json:"kills"`
+	Deaths     int     `json:"deaths"`
+	Diff       float64 `json:"diff"`
+	Rating     float64 `json:"rating"`
+	RatingDiff float64 `json:"rating_diff"`
+	MajorItems int     `json:"major_items"`
+	Control    float64 `json:"control"`
+	TimePlayed float64 `json:"time_played_seconds"`
+	JoinedLate bool    `json:"joined_late"`
+}
+
+type apiEvent struct {
+	Id        int    `json:"id"`
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	RoundId   string `json:"round_id,omitempty"`
+	Map       string `json:"map,omitempty"`
+	Attacker  string `json:"attacker,omitempty"`
+	Victim    string `json:"victim,omitempty"`
+	Weapon    string `json:"weapon,omitempty"`
+	Player    string `json:"player,omitempty"`
+	Team      string `json:"team,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// apiState is the copy of the game state served by the API
+// Rounds and events are append-only, so only new entries are copied on updates
+type apiState struct {
+	players []apiPlayer
+	rounds  []apiRound
+	events  []apiEvent
+	awards  []apiAward
+}
+
+type apiAward struct {
+	Title  string `json:"title"`
+	Player string `json:"player"`
+	Value  string `json:"value"`
+}
+
+func newAPIPlayer(p *models.Player) apiPlayer {
+	return apiPlayer{
+		Name:            p.Name,
+		Team:            p.Team.String(),
+		Rank:            p.Rank,
+		PrevRank:        p.PrevRank,
+		Score:           p.Score,
+		Score14:         p.Score14,
+		Diff:            p.Diff,
+		Diff14:          p.Diff14,
+		Kills:           p.Kills,
+		Deaths:          p.Deaths,
+		KillDeathRatio:  p.KillDeathRatio,
+		RocketKills:     p.RocketKills,
+		RailgunKills:    p.RailgunKills,
+		GauntletKills:   p.GauntletKills,
+		SuicideDeaths:   p.SuicideDeaths,
+		KillingStreak:   p.KillingStreak,
+		Captures:        p.Captures,
+		FlagReturns:     p.FlagReturns,
+		FlagTakes:       p.FlagTakes,
+		RoundKills:      p.RoundKills,
+		RoundDeaths:     p.RoundDeaths,
+		Rating:          p.Rating,
+		RatingDiff:      p.RatingDiff,
+		IsDrinkingCider: p.IsDrinkingCider,
+		IsBot:           p.IsBot,
+		Drank:           p.Drank,
+		Owed14:          p.Owed14(),
+		Items:           maps.Clone(p.Items),
+		TimePlayed:      p.TimePlayed.Seconds(),
+		KillsPerMinute:  p.KillsPerMinute(),
+		RoundJoinedLate: p.RoundJoinedLate,
+	}
+}
+
+func newAPIRound(r *models.Round) apiRound {
+	round := apiRound{
+		Id:        r.Id,
+		LegacyId:  r.LegacyId,
+		Map:       r.MapName,
+		StartedAt: r.StartedAt,
+		EndedAt:   r.EndedAt,
+		GameType:  r.GameType,
+		RedScore:  r.RedScore,
+		BlueScore: r.BlueScore,
+		Results:   make([]apiRoundResult, 0, len(r.Results)),
+
+		IgnoredReason: r.IgnoredReason,
+	}
+	for _, result := range r.Results {
+		round.Results = append(round.Results, apiRoundResult{
+			Name:       result.Name,
+			Team:       result.Team,
+			Kills:      result.Kills,
+			Deaths:     result.Deaths,
+			Diff:       result.Diff,
+			Rating:     result.Rating,
+			RatingDiff: result.RatingDiff,
+			MajorItems: result.MajorItems,
+			Control:    result.Control,
+			TimePlayed: result.TimePlayed.Seconds(),
+			JoinedLate: result.JoinedLate,
+		})
+	}
+	return round
+}
+
+func newAPIEvent(e models.Event) apiEvent {
+	return apiEvent{
+		Id:        e.Id,
+		Type:      e.Type,
+		Timestamp: e.Timestamp,
+		RoundId:   e.RoundId,
+		Map:       e.MapName,
+		Attacker:  e.Attacker,
+		Victim:    e.Victim,
+		Weapon:    e.Weapon,
+		Player:    e.Player,
+		Team:      e.Team,
+		Message:   e.Message,
+	}
+}
+
+// updateAPI copies the changes in the game into the API state
+func (s *Server) updateAPI(game *models.Snapshot) {
+	players := make([]apiPlayer, 0, len(game.Players))
+	for _, p := range game.Players {
+		players = append(players, newAPIPlayer(p))
+	}
+
+	s.apiMu.Lock()
+	defer s.apiMu.Unlock()
+
+	s.api.players = players
+	newRounds := game.Rounds[len(s.api.rounds):]
+	for _, r := range newRounds {
+		s.api.rounds = append(s.api.rounds, newAPIRound(r))
+	}
+	// Awards only change when a round is saved
+	if len(newRounds) > 0 {
+		s.api.awards = s.api.awards[:0]
+		for _, a := range game.Awards {
+			s.api.awards = append(s.api.awards, apiAward{Title: a.Title, Player: a.Player, Value: a.Value})
+		}
+	}
+	for _, e := range game.Events[len(s.api.events):] {
+		s.api.events = append(s.api.events, newAPIEvent(e))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body map[string]any) {
+	body["version"] = APIVersion
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": message})
+}
+
+// handlePlayers serves the standings, best ranked first
+func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request) {
+	s.apiMu.RLock()
+	defer s.apiMu.RUnlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"players": nonNil(s.api.players)})
+}
+
+// handleRounds serves the history of saved rounds, oldest first
+func (s *Server) handleRounds(w http.ResponseWriter, r *http.Request) {
+	s.apiMu.RLock()
+	defer s.apiMu.RUnlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"rounds": nonNil(s.api.rounds)})
+}
+
+// handleRound serves a single saved round by id or legacy hash
+func (s *Server) handleRound(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.apiMu.RLock()
+	defer s.apiMu.RUnlock()
+
+	for _, round := range s.api.rounds {
+		if round.Id == id || round.LegacyId == id {
+			writeJSON(w, http.StatusOK, map[string]any{"round": round})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "round not found: "+id)
+}
+
+// handleAwards serves the awards for the saved rounds
+func (s *Server) handleAwards(w http.ResponseWriter, r *http.Request) {
+	s.apiMu.RLock()
+	defer s.apiMu.RUnlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"awards": nonNil(s.api.awards)})
+}
+
+// handleAPIEvents serves the events after the ?since= event id, oldest first
+func (s *Server) handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	since := 0
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = strconv.Atoi(value); err != nil || since < 0 {
+			writeError(w, http.StatusBadRequest, "since must be a non-negative event id")
+			return
+		}
+	}
+
+	s.apiMu.RLock()
+	defer s.apiMu.RUnlock()
+
+	// Event ids are sequential starting from 1
+	events := []apiEvent{}
+	if since < len(s.api.events) {
+		events = s.api.events[since:]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"last_id": len(s.api.events),
+		"events":  events,
+	})
+}
+
+// nonNil makes empty lists encode as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func getJSON(t *testing.T, url string, expectedStatus int) map[string]json.RawMessage {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("GET %s: expected status %d, got %d", url, expectedStatus, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("GET %s: expected JSON content type, got %q", url, contentType)
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("GET %s: failed to decode body: %v", url, err)
+	}
+	if string(body["version"]) != "1" {
+		t.Errorf("GET %s: expected version 1, got %s", url, body["version"])
+	}
+	return body
+}
+
+func decode[T any](t *testing.T, data json.RawMessage) T {
+	t.Helper()
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("Failed to decode %s: %v", data, err)
+	}
+	return value
+}
+
+func newTestAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	game := parseLog(t,
+		"2024-04-19 16:01:17 Server: q3dm1",
+		"2024-04-19 16:02:13 Server: q3dm17",
+		"2024-04-19 16:02:20 Kill: 0 1 10: PlayerOne killed PlayerTwo by MOD_RAILGUN",
+		"2024-04-19 16:02:25 Kill: 1 0 6: PlayerTwo killed PlayerOne by MOD_ROCKET",
+		"2024-04-19 16:02:30 Kill: 0 1 2: PlayerOne killed PlayerTwo by MOD_GAUNTLET",
+		"2024-04-19 16:12:13 score: 2  ping: 0  client: 0 PlayerOne",
+	)
+	server.OnUpdate(game.Snapshot())
+
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAPI_EmptyGame(t *testing.T) {
+	server := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	for _, path := range []string{"/api/players", "/api/rounds", "/api/events"} {
+		body := getJSON(t, ts.URL+path, http.StatusOK)
+		for key, value := range body {
+			if string(value) == "null" {
+				t.Errorf("GET %s: expected %q to be set, got null", path, key)
+			}
+		}
+	}
+}
+
+func TestAPI_Players(t *testing.T) {
+	ts := newTestAPIServer(t)
+
+	players := decode[[]apiPlayer](t, getJSON(t, ts.URL+"/api/players", http.StatusOK)["players"])
+	if len(players) != 2 {
+		t.Fatalf("Expected 2 players, got %d", len(players))
+	}
+	if players[0].Name != "PlayerOne" || players[0].Rank != 1 || players[0].Kills != 2 || players[0].GauntletKills != 1 {
+		t.Errorf("Unexpected first player: %+v", players[0])
+	}
+	if players[1].Name != "PlayerTwo" || players[1].Rank != 2 || players[1].RocketKills != 1 {
+		t.Errorf("Unexpected second player: %+v", players[1])
+	}
+}
+
+func TestAPI_Rounds(t *testing.T) {
+	ts := newTestAPIServer(t)
+
+	rounds := decode[[]apiRound](t, getJSON(t, ts.URL+"/api/rounds", http.StatusOK)["rounds"])
+	if len(rounds) != 1 {
+		t.Fatalf("Expected 1 round, got %d", len(rounds))
+	}
+	if rounds[0].Map != "q3dm17" || len(rounds[0].Results) != 2 {
+		t.Errorf("Unexpected round: %+v", rounds[0])
+	}
+
+	round := decode[apiRound](t, getJSON(t, ts.URL+"/api/rounds/"+rounds[0].Id, http.StatusOK)["round"])
+	if round.Id != rounds[0].Id || round.Results[0].Name != "PlayerOne" || round.Results[0].Diff != 1 {
+		t.Errorf("Unexpected round: %+v", round)
+	}
+
+	legacy := decode[apiRound](t, getJSON(t, ts.URL+"/api/rounds/"+rounds[0].LegacyId, http.StatusOK)["round"])
+	if legacy.Id != rounds[0].Id {
+		t.Errorf("Expected the round to be found by its legacy id, got %+v", legacy)
+	}
+
+	body := getJSON(t, ts.URL+"/api/rounds/unknown", http.StatusNotFound)
+	if _, ok := body["error"]; !ok {
+		t.Error("Expected error message for unknown round")
+	}
+}
+
+func TestAPI_EventsSince(t *testing.T) {
+	ts := newTestAPIServer(t)
+
+	// 2 map changes, 3 kills and a saved round
+	body := getJSON(t, ts.URL+"/api/events", http.StatusOK)
+	events := decode[[]apiEvent](t, body["events"])
+	if len(events) != 6 || string(body["last_id"]) != "6" {
+		t.Fatalf("Expected 6 events, got %d (last id %s)", len(events), body["last_id"])
+	}
+	if events[0].Type != "map_change" || events[0].Map != "q3dm1" || events[0].Id != 1 {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[5].Type != "round_saved" {
+		t.Errorf("Unexpected last event: %+v", events[5])
+	}
+
+	events = decode[[]apiEvent](t, getJSON(t, ts.URL+"/api/events?since=4", http.StatusOK)["events"])
+	if len(events) != 2 || events[0].Id != 5 || events[0].Attacker != "PlayerOne" || events[0].Weapon != "MOD_GAUNTLET" {
+		t.Errorf("Unexpected events since 4: %+v", events)
+	}
+
+	events = decode[[]apiEvent](t, getJSON(t, ts.URL+"/api/events?since=100", http.StatusOK)["events"])
+	if len(events) != 0 {
+		t.Errorf("Expected no events after the last id, got %d", len(events))
+	}
+
+	getJSON(t, ts.URL+"/api/events?since=abc", http.StatusBadRequest)
+}
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Server serves the live web dashboard, pushing game updates to the
+// connected browsers using Server-Sent Events, and the read-only JSON API
+type Server struct {
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	latest  []byte
+	clients map[chan []byte]struct{}
+
+	apiMu sync.RWMutex
+	api   apiState
+}
+
+// NewServer creates a dashboard server without any connected browsers
+func NewServer(logger *slog.Logger) *Server {
+	return &Server{
+		logger:  logger.With("component", "http"),
+		clients: make(map[chan []byte]struct{}),
+	}
+}
+
+// OnUpdate implements parser.Subscriber
+// The game state is encoded right away, so browsers only get the JSON
+func (s *Server) OnUpdate(snapshot *models.Snapshot) {
+	s.updateAPI(snapshot)
+
+	data, err := json.Marshal(newState(snapshot))
+	if err != nil {
+		s.logger.Error("Failed to encode game state", "error", err)
+		return
+	}
+
+	s.broadcast(data)
+}
+
+// broadcast stores the latest state and hands it to every connected browser
+// Browsers that are behind only receive the latest state
+func (s *Server) broadcast(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latest = data
+	for client := range s.clients {
+		select {
+		case <-client:
+		default:
+		}
+		client <- data
+	}
+}
+
+func (s *Server) subscribe() (chan []byte, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client := make(chan []byte, 1)
+	s.clients[client] = struct{}{}
+	return client, s.latest
+}
+
+func (s *Server) unsubscribe(client chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.clients, client)
+}
+
+// Handler returns the HTTP handler serving the dashboard, the streaming overlay and the API
+func (s *Server) Handler() http.Handler {
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServer(http.FS(static)))
+	mux.HandleFunc("GET /overlay", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, static, "overlay.html")
+	})
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("GET /api/players", s.handlePlayers)
+	mux.HandleFunc("GET /api/rounds", s.handleRounds)
+	mux.HandleFunc("GET /api/rounds/{id}", s.handleRound)
+	mux.HandleFunc("GET /api/events", s.handleAPIEvents)
+	mux.HandleFunc("GET /api/awards", s.handleAwards)
+	return mux
+}
+
+// ListenAndServe serves the dashboard on the given address, e.g. ":8080"
+func (s *Server) ListenAndServe(addr string) error {
+	s.logger.Info("Serving dashboard", "address", addr)
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+// handleEvents streams the game state to a browser, starting with the latest state
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client, latest := s.subscribe()
+	defer s.unsubscribe(client)
+
+	if latest != nil {
+		fmt.Fprintf(w, "data: %s\n\n", latest)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-client:
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
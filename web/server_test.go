@@ -0,0 +1,156 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+	"github.com/fjerlv/deathquake-go/parser"
+)
+
+// parseLog parses the log lines into a new game
+func parseLog(t *testing.T, lines ...string) *models.Game {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	game := models.NewGame(&config.Config{}, logger)
+	receivingScores := false
+	for _, line := range lines {
+		var err error
+		if err, receivingScores = parser.ParseLine(line, game, logger, receivingScores); err != nil {
+			t.Fatalf("Failed to parse %q: %v", line, err)
+		}
+	}
+	return game
+}
+
+// readEvent reads the next Server-Sent Event data line
+func readEvent(t *testing.T, reader *bufio.Reader) state {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var s state
+			if err := json.Unmarshal([]byte(data), &s); err != nil {
+				t.Fatalf("Failed to decode event %q: %v", data, err)
+			}
+			return s
+		}
+	}
+}
+
+func TestHandler_ServesDashboard(t *testing.T) {
+	server := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "EventSource") {
+		t.Error("Expected dashboard page to subscribe to events")
+	}
+}
+
+func TestHandler_ServesOverlay(t *testing.T) {
+	server := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/overlay?top=3&layout=horizontal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Expected HTML content type, got %q", resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "background: transparent") {
+		t.Error("Expected overlay to have a transparent background")
+	}
+}
+
+func TestEvents_StreamsGameUpdates(t *testing.T) {
+	server := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	game := parseLog(t,
+		"2024-04-19 16:01:17 Server: q3dm1",
+		"2024-04-19 16:02:13 Server: q3dm17",
+		"2024-04-19 16:03:00 Kill: 0 1 10: PlayerOne killed PlayerTwo by MOD_RAILGUN",
+	)
+	server.OnUpdate(game.Snapshot())
+
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Expected event stream content type, got %q", contentType)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	// A new browser gets the latest state right away
+	s := readEvent(t, reader)
+	if s.Map != "q3dm17" || s.RoundStartedAt != "2024-04-19 16:02:13" || s.Time != "2024-04-19 16:03:00" {
+		t.Errorf("Unexpected round info: %+v", s)
+	}
+	if len(s.KillFeed) != 1 || s.KillFeed[0].Attacker != "PlayerOne" || s.KillFeed[0].Weapon != "MOD_RAILGUN" {
+		t.Errorf("Unexpected kill feed: %+v", s.KillFeed)
+	}
+	if len(s.Players) != 2 {
+		t.Errorf("Expected 2 players, got %d", len(s.Players))
+	}
+
+	// Saving the round is pushed with the winner
+	done := make(chan state)
+	go func() {
+		done <- readEvent(t, reader)
+	}()
+
+	game.Save()
+	server.OnUpdate(game.Snapshot())
+
+	select {
+	case s = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for game update")
+	}
+	if s.RoundsPlayed != 1 {
+		t.Errorf("Expected 1 round played, got %d", s.RoundsPlayed)
+	}
+	if s.RoundWinner != "PlayerOne" {
+		t.Errorf("Expected PlayerOne to be round winner, got %q", s.RoundWinner)
+	}
+	if s.Players[0].Name != "PlayerOne" || s.Players[0].Rank != 1 || !s.Players[0].IsRoundWinner {
+		t.Errorf("Unexpected standings: %+v", s.Players)
+	}
+}
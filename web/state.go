@@ -0,0 +1,95 @@
+package web
+
+import (
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// state is the dashboard view of the game sent to the browsers
+type state struct {
+	Map            string        `json:"map"`
+	RoundId        string        `json:"round_id"`
+	IsWarmup       bool          `json:"is_warmup"`
+	RoundStartedAt string        `json:"round_started_at"`
+	Time           string        `json:"time"`
+	RoundsPlayed   int           `json:"rounds_played"`
+	GameWinner     string        `json:"game_winner"`
+	RoundWinner    string        `json:"round_winner"`
+	Players        []playerState `json:"players"`
+	KillFeed       []killState   `json:"kill_feed"`
+}
+
+type playerState struct {
+	Rank          int     `json:"rank"`
+	PrevRank      int     `json:"prev_rank"`
+	Name          string  `json:"name"`
+	Score         float64 `json:"score"`
+	Score14       string  `json:"score14"`
+	Diff14        string  `json:"diff14"`
+	Kills         int     `json:"kills"`
+	Deaths        int     `json:"deaths"`
+	RoundKills    int     `json:"round_kills"`
+	Rating        float64 `json:"rating"`
+	RatingDiff    float64 `json:"rating_diff"`
+	IsRoundWinner bool    `json:"is_round_winner"`
+	IsGameWinner  bool    `json:"is_game_winner"`
+}
+
+type killState struct {
+	Timestamp string `json:"timestamp"`
+	Attacker  string `json:"attacker"`
+	Victim    string `json:"victim"`
+	Weapon    string `json:"weapon"`
+}
+
+// newState builds the dashboard state from a game update
+func newState(game *models.Snapshot) state {
+	s := state{
+		Map:            game.CurrentMapName,
+		RoundId:        game.CurrentRoundId,
+		IsWarmup:       game.IsWarmup,
+		RoundStartedAt: game.CurrentRoundStart,
+		Time:           game.CurrentTime,
+		RoundsPlayed:   game.RoundsPlayed(),
+		Players:        make([]playerState, 0, len(game.Players)),
+		KillFeed:       make([]killState, 0, len(game.KillFeed)),
+	}
+
+	for _, p := range game.Players {
+		s.Players = append(s.Players, newPlayerState(p))
+		if p.IsGameWinner() {
+			s.GameWinner = p.Name
+		}
+		if p.IsRoundWinner() {
+			s.RoundWinner = p.Name
+		}
+	}
+
+	for _, k := range game.KillFeed {
+		s.KillFeed = append(s.KillFeed, killState{
+			Timestamp: k.Timestamp,
+			Attacker:  k.Attacker,
+			Victim:    k.Victim,
+			Weapon:    k.Weapon,
+		})
+	}
+
+	return s
+}
+
+func newPlayerState(p *models.Player) playerState {
+	return playerState{
+		Rank:          p.Rank,
+		PrevRank:      p.PrevRank,
+		Name:          p.Name,
+		Score:         p.Score,
+		Score14:       p.Score14,
+		Diff14:        p.Diff14,
+		Kills:         p.Kills,
+		Deaths:        p.Deaths,
+		RoundKills:    p.RoundKills,
+		Rating:        p.Rating,
+		RatingDiff:    p.RatingDiff,
+		IsRoundWinner: p.IsRoundWinner(),
+		IsGameWinner:  p.IsGameWinner(),
+	}
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// Message is the JSON posted to the webhook
+// Discord reads content and Slack reads text, so both are set
+type Message struct {
+	Content string `json:"content"`
+	Text    string `json:"text"`
+}
+
+func newMessage(text string) Message {
+	return Message{Content: text, Text: text}
+}
+
+// roundIgnored tells why a round does not count
+func roundIgnored(round *models.Round) string {
+	return fmt.Sprintf("🚫 Round on %s ignored: %s\n", round.MapName, round.IgnoredReason)
+}
+
+// roundSummary describes a saved round with the standings after it
+// The parser sends an update for every ended round, so players hold the
+// standings right after the round
+func roundSummary(round *models.Round, players []*models.Player) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "🏁 Round on %s finished", round.MapName)
+	if len(round.Results) > 0 {
+		winner := round.Results[0]
+		fmt.Fprintf(&sb, ", %s won with %d kills", winner.Name, winner.Kills)
+	}
+	sb.WriteString("\n")
+
+	for _, p := range players {
+		if p.Rank == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s. %s", rankChange(p.Rank, p.PrevRank), p.Name)
+		if p.Diff14 != "" {
+			fmt.Fprintf(&sb, " +%s", p.Diff14)
+		}
+		if p.Score14 != "" {
+			fmt.Fprintf(&sb, " (%s)", p.Score14)
+		}
+		sb.WriteString("\n")
+	}
+
+	// Let the judge know who might not have played the full round
+	if late := round.LateJoiners(); len(late) > 0 {
+		fmt.Fprintf(&sb, "⏱ Joined late: %s\n", strings.Join(late, ", "))
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// gameWinnerAnnouncement announces a player passing the winning score
+func gameWinnerAnnouncement(p *models.Player) string {
+	return fmt.Sprintf("🏆 %s passed the winning score of %d with %s and wins the game!",
+		p.Name, models.WinningScore, p.Score14)
+}
+
+// rankChange formats the rank with the change since the previous round, e.g. "3 (▲2)"
+func rankChange(rank, prevRank int) string {
+	if prevRank == 0 || rank == prevRank {
+		return fmt.Sprintf("%d", rank)
+	}
+	if rank < prevRank {
+		return fmt.Sprintf("%d (▲%d)", rank, prevRank-rank)
+	}
+	return fmt.Sprintf("%d (▼%d)", rank, rank-prevRank)
+}
@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+const (
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+	requestTimeout    = 10 * time.Second
+)
+
+// Notifier posts round results and the game winner to a webhook
+// Messages are queued and delivered in the background by Run, so a slow or
+// flaky network never holds up the parser
+type Notifier struct {
+	url    string
+	logger *slog.Logger
+	client *http.Client
+	queue  *queue
+
+	// wake signals Run that a message was queued
+	wake chan struct{}
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	// Number of rounds in the game already seen
+	rounds int
+}
+
+// NewNotifier creates a notifier posting to url, loading messages that were
+// not delivered before the last shutdown from queueFile
+func NewNotifier(url string, queueFile string, logger *slog.Logger) (*Notifier, error) {
+	q, err := loadQueue(queueFile)
+	if err != nil {
+		return nil, err
+	}
+	logger = logger.With("component", "webhook")
+	if q.len() > 0 {
+		logger.Info("Loaded undelivered messages", "messages", q.len())
+	}
+
+	return &Notifier{
+		url:        url,
+		logger:     logger,
+		client:     &http.Client{Timeout: requestTimeout},
+		queue:      q,
+		wake:       make(chan struct{}, 1),
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}, nil
+}
+
+// OnUpdate implements parser.Subscriber
+// It queues a summary for every newly saved round, a note for every ignored
+// round and an announcement when a player passes the winning score
+func (n *Notifier) OnUpdate(game *models.Snapshot) {
+	// Rounds replayed while catching up on an old log are not notified
+	if !models.IsLive(game.CurrentTime) {
+		n.rounds = len(game.Rounds)
+		return
+	}
+	if len(game.Rounds) == 0 {
+		return
+	}
+	// An event is told apart by its first round
+	event := game.Rounds[0].Key()
+
+	for i := n.rounds; i < len(game.Rounds); i++ {
+		round := game.Rounds[i]
+		content := roundSummary(round, game.Players)
+		if round.IsIgnored() {
+			content = roundIgnored(round)
+		}
+		queued, err := n.queue.pushRound(event, i, newMessage(content))
+		n.queued(queued, err)
+	}
+	n.rounds = len(game.Rounds)
+
+	for _, p := range game.Players {
+		if p.IsGameWinner() {
+			queued, err := n.queue.pushGameWinner(event, p.Name, newMessage(gameWinnerAnnouncement(p)))
+			n.queued(queued, err)
+		}
+	}
+}
+
+// queued wakes up Run when a message was added to the queue
+func (n *Notifier) queued(queued bool, err error) {
+	if err != nil {
+		n.logger.Error("Failed to queue message", "error", err)
+	}
+	if !queued {
+		return
+	}
+
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers queued messages in order until ctx is cancelled
+// Failed deliveries are retried with exponential backoff
+func (n *Notifier) Run(ctx context.Context) {
+	backoff := n.minBackoff
+	for {
+		message, ok := n.queue.peek()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-n.wake:
+				continue
+			}
+		}
+
+		err := n.post(ctx, message)
+		if err == nil || isPermanent(err) {
+			if err != nil {
+				n.logger.Warn("Dropping message", "error", err)
+			}
+			if err := n.queue.pop(); err != nil {
+				n.logger.Error("Failed to remove delivered message", "error", err)
+			}
+			backoff = n.minBackoff
+			continue
+		}
+
+		n.logger.Warn("Delivery failed, retrying", "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, n.maxBackoff)
+	}
+}
+
+// statusError is returned when the webhook responds with a non-2xx status
+type statusError struct {
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.statusCode)
+}
+
+// isPermanent returns true for errors where retrying the same message will not help
+func isPermanent(err error) bool {
+	statusErr, ok := err.(*statusError)
+	if !ok {
+		return false
+	}
+	return statusErr.statusCode >= 400 && statusErr.statusCode < 500 &&
+		statusErr.statusCode != http.StatusTooManyRequests
+}
+
+func (n *Notifier) post(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
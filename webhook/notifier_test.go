@@ -0,0 +1,293 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fjerlv/deathquake-go/config"
+	"github.com/fjerlv/deathquake-go/models"
+)
+
+// webhookStandIn is a local webhook that fails the first requests
+type webhookStandIn struct {
+	mu       sync.Mutex
+	failures int
+	received []Message
+}
+
+func (s *webhookStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var message Message
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.received = append(s.received, message)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *webhookStandIn) messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.received...)
+}
+
+// waitForMessages waits until the stand-in has received count messages
+func (s *webhookStandIn) waitForMessages(t *testing.T, count int) []Message {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if messages := s.messages(); len(messages) >= count {
+			return messages
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d messages, got %d", count, len(s.messages()))
+	return nil
+}
+
+func newTestNotifier(t *testing.T, url string, queueFile string) *Notifier {
+	t.Helper()
+
+	notifier, err := NewNotifier(url, queueFile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.minBackoff = time.Millisecond
+	notifier.maxBackoff = 10 * time.Millisecond
+	return notifier
+}
+
+// playRound plays a round on a new map where the winner kills the loser the given number of times
+// The round starts at timestamp and ends just now
+func playRound(game *models.Game, mapName, timestamp, winner, loser string, kills int) {
+	// The first map of a game is warmup
+	if game.CurrentMapName == "" {
+		game.NewMap("q3dm1", timestamp)
+	}
+	game.NewMap(mapName, timestamp)
+	for i := 0; i < kills; i++ {
+		game.RecordKill(winner, loser, "MOD_RAILGUN")
+	}
+	game.RecordKill(loser, winner, "MOD_RAILGUN")
+	game.CurrentTime = time.Now().Format(models.TimestampLayout)
+	game.Save()
+}
+
+func send(notifier *Notifier, game *models.Game) {
+	notifier.OnUpdate(game.Snapshot())
+}
+
+func TestNotifier_PostsRoundSummaryWithRetry(t *testing.T) {
+	standIn := &webhookStandIn{failures: 2}
+	ts := httptest.NewServer(standIn)
+	defer ts.Close()
+
+	notifier := newTestNotifier(t, ts.URL, filepath.Join(t.TempDir(), "queue.json"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notifier.Run(ctx)
+
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	send(notifier, game)
+	playRound(game, "q3dm17", "2024-04-19 16:02:13", "PlayerOne", "PlayerTwo", 10)
+	send(notifier, game)
+	send(notifier, game)
+
+	messages := standIn.waitForMessages(t, 1)
+	time.Sleep(20 * time.Millisecond)
+	if messages = standIn.messages(); len(messages) != 1 {
+		t.Fatalf("Expected exactly 1 message, got %d", len(messages))
+	}
+
+	content := messages[0].Content
+	if messages[0].Text != content {
+		t.Error("Expected Slack text to match Discord content")
+	}
+	for _, expected := range []string{
+		"Round on q3dm17 finished, PlayerOne won with 10 kills",
+		"1. PlayerOne +1 beer (1 beer)",
+		"2. PlayerTwo +1 sip (1 sip)",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", expected, content)
+		}
+	}
+	if notifier.queue.len() != 0 {
+		t.Errorf("Expected queue to be empty after delivery, got %d messages", notifier.queue.len())
+	}
+}
+
+func TestNotifier_AnnouncesGameWinnerOnce(t *testing.T) {
+	notifier := newTestNotifier(t, "http://127.0.0.1:0", filepath.Join(t.TempDir(), "queue.json"))
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for i := 0; i <= models.WinningScore; i++ {
+		playRound(game, fmt.Sprintf("q3dm%d", i%2+2), fmt.Sprintf("2024-04-19 16:%02d:00", i), "Winner", "Loser", 10)
+		send(notifier, game)
+	}
+	send(notifier, game)
+
+	// One summary per round and a single winner announcement
+	if notifier.queue.len() != models.WinningScore+2 {
+		t.Fatalf("Expected %d queued messages, got %d", models.WinningScore+2, notifier.queue.len())
+	}
+	last := notifier.queue.state.Messages[notifier.queue.len()-1]
+	if !strings.Contains(last.Content, "Winner passed the winning score") {
+		t.Errorf("Expected game winner announcement, got %q", last.Content)
+	}
+}
+
+func TestNotifier_AnnouncesGameWinnerOfEveryEvent(t *testing.T) {
+	queueFile := filepath.Join(t.TempDir(), "queue.json")
+
+	// Native logs without g_timestamp give every event the same round ids
+	for event, winner := range []string{"Winner", "NextWinner"} {
+		notifier := newTestNotifier(t, "http://127.0.0.1:0", queueFile)
+		game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		for i := 0; i <= models.WinningScore; i++ {
+			playRound(game, fmt.Sprintf("q3dm%d", i%2+2), fmt.Sprintf("2000-01-01 00:%02d:00", i), winner, "Loser", 10)
+			send(notifier, game)
+		}
+
+		// The queue is not delivered, so every event adds its rounds and winner
+		last := notifier.queue.state.Messages[notifier.queue.len()-1]
+		if !strings.Contains(last.Content, winner+" passed the winning score") {
+			t.Errorf("%s: expected game winner announcement, got %q", winner, last.Content)
+		}
+		if notifier.queue.state.RoundsNotified != models.WinningScore+1 {
+			t.Errorf("%s: expected %d rounds notified, got %d", winner, models.WinningScore+1, notifier.queue.state.RoundsNotified)
+		}
+		if expected := (event + 1) * (models.WinningScore + 2); notifier.queue.len() != expected {
+			t.Errorf("%s: expected %d queued messages, got %d", winner, expected, notifier.queue.len())
+		}
+	}
+}
+
+func TestNotifier_SkipsRoundsWhileCatchingUp(t *testing.T) {
+	notifier := newTestNotifier(t, "http://127.0.0.1:0", filepath.Join(t.TempDir(), "queue.json"))
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// A round from an old log
+	game.NewMap("q3dm1", "2024-04-19 16:01:17")
+	game.NewMap("q3dm17", "2024-04-19 16:02:13")
+	game.RecordKill("PlayerOne", "PlayerTwo", "MOD_RAILGUN")
+	game.CurrentTime = "2024-04-19 16:12:13"
+	game.Save()
+	send(notifier, game)
+	if notifier.queue.len() != 0 {
+		t.Fatalf("Expected the old round not to be notified, got %d messages", notifier.queue.len())
+	}
+
+	// Caught up, only the live round is notified
+	playRound(game, "q3dm6", "2024-04-19 16:13:00", "PlayerOne", "PlayerTwo", 5)
+	send(notifier, game)
+	if notifier.queue.len() != 1 || !strings.Contains(notifier.queue.state.Messages[0].Content, "q3dm6") {
+		t.Errorf("Expected only the live round to be notified, got %+v", notifier.queue.state.Messages)
+	}
+}
+
+func TestNotifier_QueueSurvivesRestart(t *testing.T) {
+	queueFile := filepath.Join(t.TempDir(), "queue.json")
+
+	// The network is down while the round is saved
+	offline := newTestNotifier(t, "http://127.0.0.1:0", queueFile)
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	playRound(game, "q3dm17", "2024-04-19 16:02:13", "PlayerOne", "PlayerTwo", 5)
+	send(offline, game)
+
+	// After a restart the log is parsed again and the queued message is delivered once
+	standIn := &webhookStandIn{}
+	ts := httptest.NewServer(standIn)
+	defer ts.Close()
+
+	online := newTestNotifier(t, ts.URL, queueFile)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go online.Run(ctx)
+
+	send(online, game)
+
+	messages := standIn.waitForMessages(t, 1)
+	time.Sleep(20 * time.Millisecond)
+	if messages = standIn.messages(); len(messages) != 1 {
+		t.Fatalf("Expected exactly 1 message, got %d", len(messages))
+	}
+	if !strings.Contains(messages[0].Content, "PlayerOne won with 5 kills") {
+		t.Errorf("Unexpected message: %q", messages[0].Content)
+	}
+}
+
+func TestNotifier_DropsRejectedMessages(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	notifier := newTestNotifier(t, ts.URL, filepath.Join(t.TempDir(), "queue.json"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notifier.Run(ctx)
+
+	game := models.NewGame(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	playRound(game, "q3dm17", "2024-04-19 16:02:13", "PlayerOne", "PlayerTwo", 5)
+	send(notifier, game)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for notifier.queue.len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if notifier.queue.len() != 0 {
+		t.Fatal("Expected rejected message to be dropped")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 {
+		t.Errorf("Expected rejected message not to be retried, got %d requests", requests)
+	}
+}
+
+func TestRankChange(t *testing.T) {
+	tests := []struct {
+		rank     int
+		prevRank int
+		expected string
+	}{
+		{rank: 1, prevRank: 0, expected: "1"},
+		{rank: 2, prevRank: 2, expected: "2"},
+		{rank: 1, prevRank: 3, expected: "1 (▲2)"},
+		{rank: 4, prevRank: 3, expected: "4 (▼1)"},
+	}
+
+	for _, tt := range tests {
+		if result := rankChange(tt.rank, tt.prevRank); result != tt.expected {
+			t.Errorf("rankChange(%d, %d) = %q, want %q", tt.rank, tt.prevRank, result, tt.expected)
+		}
+	}
+}
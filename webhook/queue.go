@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// queue holds undelivered messages in memory and mirrors them to a file,
+// so notifications survive a restart while the network is down
+// It also remembers what has been queued for the current event, so re-reading
+// the same log after a restart does not announce the same rounds again
+type queue struct {
+	filepath string
+
+	mu    sync.Mutex
+	state queueState
+}
+
+type queueState struct {
+	Messages []Message `json:"messages"`
+
+	// Event is the key of the first round of the event being notified
+	Event string `json:"event"`
+
+	// RoundsNotified is the number of rounds of the event already queued
+	RoundsNotified int `json:"rounds_notified"`
+
+	// GameWinner is the player already announced as the winner of the event
+	GameWinner string `json:"game_winner"`
+}
+
+// loadQueue loads the undelivered messages from a file
+// A missing file is not an error and results in an empty queue
+func loadQueue(filepath string) (*queue, error) {
+	q := &queue{filepath: filepath}
+
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return q, nil
+		}
+		return nil, fmt.Errorf("failed to read webhook queue: %w", err)
+	}
+	if err := json.Unmarshal(data, &q.state); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook queue: %w", err)
+	}
+
+	return q, nil
+}
+
+// startEvent forgets what was notified when the event is not the one being notified
+func (q *queue) startEvent(event string) {
+	if q.state.Event == event {
+		return
+	}
+	q.state.Event = event
+	q.state.RoundsNotified = 0
+	q.state.GameWinner = ""
+}
+
+// pushRound queues the summary of the round at index in the event's round
+// history unless it was queued before
+func (q *queue) pushRound(event string, index int, message Message) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.startEvent(event)
+	if index < q.state.RoundsNotified {
+		return false, nil
+	}
+	q.state.RoundsNotified = index + 1
+	q.state.Messages = append(q.state.Messages, message)
+	return true, q.save()
+}
+
+// pushGameWinner queues the game winner announcement unless the player was
+// announced for the event before
+func (q *queue) pushGameWinner(event string, playerName string, message Message) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.startEvent(event)
+	if q.state.GameWinner == playerName {
+		return false, nil
+	}
+	q.state.GameWinner = playerName
+	q.state.Messages = append(q.state.Messages, message)
+	return true, q.save()
+}
+
+// peek returns the oldest message without removing it
+func (q *queue) peek() (Message, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.state.Messages) == 0 {
+		return Message{}, false
+	}
+	return q.state.Messages[0], true
+}
+
+// pop removes the oldest message
+func (q *queue) pop() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.state.Messages) == 0 {
+		return nil
+	}
+	q.state.Messages = q.state.Messages[1:]
+	return q.save()
+}
+
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.state.Messages)
+}
+
+// save writes the queue to a temporary file and renames it into place,
+// so a crash never leaves a half written queue behind
+func (q *queue) save() error {
+	data, err := json.MarshalIndent(q.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook queue: %w", err)
+	}
+
+	tmp := q.filepath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write webhook queue: %w", err)
+	}
+	if err := os.Rename(tmp, q.filepath); err != nil {
+		return fmt.Errorf("failed to write webhook queue: %w", err)
+	}
+	return nil
+}